@@ -0,0 +1,571 @@
+package antstyle
+
+import (
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// freeChars 为通配符挑选示例字符时依次尝试的候选字符
+const freeChars = "xyzabcdefghijklmnopqrstuvw0123456789"
+
+// patternVariant 模式可匹配路径的一部分：固定的段序列，以及允许的结尾分隔符状态
+type patternVariant struct {
+	segments []*patternSegment
+	trailing []bool
+}
+
+// patternLanguage 模式可匹配的全部路径，按doMatch的语义拆成若干patternVariant
+/**
+ *不含"**"的模式只匹配段数相同、结尾分隔符与模式一致的路径；
+ *若其最后一段为"*"，还匹配少一段且以分隔符结尾的路径（例如"/a/*"匹配"/a/"）。
+ *含有"**"的模式不检查结尾分隔符。
+ */
+type patternLanguage struct {
+	leading  bool
+	variants []patternVariant
+}
+
+// Covers 判断模式a是否匹配模式b所能匹配的每一条路径
+/**
+ *在compilePattern产生的段结构上进行符号计算：字面量、"*"/"?"通配、{var}与"**"。
+ *带正则约束的变量{name:regex}无法精确比较，此时结果是保守的：返回true时一定成立，返回false时未必不成立。
+ *@param a 覆盖方模式
+ *@param b 被覆盖方模式
+ *@return bool 如果a匹配b能匹配的所有路径，返回true
+ */
+func (ant *AntPathMatcher) Covers(a, b string) bool {
+	langA := ant.patternLanguage(a)
+	langB := ant.patternLanguage(b)
+	if langA.leading != langB.leading {
+		return false
+	}
+	for _, variantB := range langB.variants {
+		for _, trailing := range variantB.trailing {
+			covered := false
+			for _, variantA := range langA.variants {
+				if containsBool(variantA.trailing, trailing) && ant.sequenceCovers(variantA.segments, variantB.segments) {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Overlaps 判断模式a与模式b是否能匹配同一条路径
+/**
+ *与Covers一样在段结构上进行符号计算，发生重叠时返回一条两个模式都能匹配的具体路径。
+ *返回的示例路径都经过Match验证。
+ *@param a 第一个模式
+ *@param b 第二个模式
+ *@return bool 是否存在同时被两个模式匹配的路径
+ *@return string 这样的一条示例路径，不重叠时为空字符串
+ */
+func (ant *AntPathMatcher) Overlaps(a, b string) (bool, string) {
+	langA := ant.patternLanguage(a)
+	langB := ant.patternLanguage(b)
+	if langA.leading != langB.leading {
+		return false, ""
+	}
+	for _, variantA := range langA.variants {
+		for _, variantB := range langB.variants {
+			trailing := make([]bool, 0, 2)
+			for _, t := range variantA.trailing {
+				if containsBool(variantB.trailing, t) {
+					trailing = append(trailing, t)
+				}
+			}
+			if len(trailing) == 0 {
+				continue
+			}
+			segments, endsWithSeparator, ok := ant.sequenceOverlap(variantA.segments, variantB.segments, langA.leading, trailing)
+			if !ok {
+				continue
+			}
+			example := ant.buildPath(langA.leading, segments, endsWithSeparator)
+			if ant.Match(a, example) && ant.Match(b, example) {
+				return true, example
+			}
+		}
+	}
+	return false, ""
+}
+
+// patternLanguage 将模式拆分为段并按doMatch的语义整理出可匹配的路径集合
+func (ant *AntPathMatcher) patternLanguage(pattern string) *patternLanguage {
+	texts := make([]string, 0)
+	for _, token := range ant.tokenizePath(pattern) {
+		texts = append(texts, *token)
+	}
+	texts = collapseWildcardRuns(texts)
+	segments := make([]*patternSegment, len(texts))
+	hasDoubleWildcard := false
+	for i, text := range texts {
+		segments[i] = parseSegment(text)
+		if segments[i].kind == DoubleWildcardSegment {
+			hasDoubleWildcard = true
+		}
+	}
+
+	lang := &patternLanguage{leading: strings.HasPrefix(pattern, ant.pathSeparator)}
+	if hasDoubleWildcard {
+		lang.variants = append(lang.variants, patternVariant{segments: segments, trailing: []bool{false, true}})
+		return lang
+	}
+	lang.variants = append(lang.variants, patternVariant{segments: segments, trailing: []bool{strings.HasSuffix(pattern, ant.pathSeparator)}})
+	if last := len(texts) - 1; last >= 0 && texts[last] == "*" {
+		lang.variants = append(lang.variants, patternVariant{segments: segments[:last], trailing: []bool{true}})
+	}
+	return lang
+}
+
+// sequenceCovers 判断段序列a是否覆盖段序列b
+/**
+ *covers[i][j]表示a[i:]覆盖b[j:]。a中的"**"连同紧随其后的k个"*"（collapseWildcardRuns保证了这种写法）
+ *匹配至少k个任意段，因此可以吸收b中任意一段至少含有k个非"**"段的连续段；
+ *其余的段必须逐一被segmentCovers覆盖。
+ */
+func (ant *AntPathMatcher) sequenceCovers(a, b []*patternSegment) bool {
+	n, m := len(a), len(b)
+	covers := make([][]bool, n+1)
+	for i := range covers {
+		covers[i] = make([]bool, m+1)
+	}
+	covers[n][m] = true
+	for i := n - 1; i >= 0; i-- {
+		if a[i].kind == DoubleWildcardSegment {
+			end := i + 1
+			for end < n && a[end].text == "*" {
+				end++
+			}
+			singles := end - i - 1
+			for j := m; j >= 0; j-- {
+				for k, count := j, 0; k <= m; k++ {
+					if count >= singles && covers[end][k] {
+						covers[i][j] = true
+						break
+					}
+					if k < m && b[k].kind != DoubleWildcardSegment {
+						count++
+					}
+				}
+			}
+			continue
+		}
+		for j := m - 1; j >= 0; j-- {
+			if b[j].kind != DoubleWildcardSegment {
+				covers[i][j] = covers[i+1][j+1] && ant.segmentCovers(a[i], b[j])
+			}
+		}
+	}
+	return covers[0][0]
+}
+
+// overlapState sequenceOverlap与tokensOverlap在乘积自动机上搜索时的状态
+type overlapState struct {
+	i, j     int
+	nonEmpty bool
+}
+
+// overlapTrace 记录到达某个状态的上一个状态以及途中产生的段或字符
+type overlapTrace struct {
+	prev     overlapState
+	emitted  string
+	consumed bool
+}
+
+// segmentMatch 两个模式段都能匹配的示例段
+type segmentMatch struct {
+	text  string
+	found bool
+}
+
+// sequenceOverlap 在两个段序列的乘积上做广度优先搜索，寻找两者都能匹配的段序列
+func (ant *AntPathMatcher) sequenceOverlap(a, b []*patternSegment, leading bool, trailing []bool) ([]string, bool, bool) {
+	n, m := len(a), len(b)
+	start := overlapState{}
+	traces := map[overlapState]overlapTrace{start: {}}
+	queue := []overlapState{start}
+	witnesses := make(map[[2]int]segmentMatch)
+	push := func(next, prev overlapState, emitted string, consumed bool) {
+		if _, ok := traces[next]; !ok {
+			traces[next] = overlapTrace{prev: prev, emitted: emitted, consumed: consumed}
+			queue = append(queue, next)
+		}
+	}
+
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		i, j := state.i, state.j
+		if i == n && j == m {
+			endsWithSeparator, ok := chooseTrailing(leading, state.nonEmpty, trailing)
+			if ok {
+				segments := make([]string, 0)
+				for current := state; current != start; current = traces[current].prev {
+					if trace := traces[current]; trace.consumed {
+						segments = append([]string{trace.emitted}, segments...)
+					}
+				}
+				return segments, endsWithSeparator, true
+			}
+			continue
+		}
+		doubleA := i < n && a[i].kind == DoubleWildcardSegment
+		doubleB := j < m && b[j].kind == DoubleWildcardSegment
+		if doubleA {
+			push(overlapState{i + 1, j, state.nonEmpty}, state, "", false)
+		}
+		if doubleB {
+			push(overlapState{i, j + 1, state.nonEmpty}, state, "", false)
+		}
+		if i == n || j == m {
+			continue
+		}
+		key := [2]int{i, j}
+		witness, ok := witnesses[key]
+		if !ok {
+			switch {
+			case doubleA && doubleB:
+				witness = segmentMatch{ant.freeSegment(), true}
+			case doubleA:
+				witness.text, witness.found = ant.segmentWitness(b[j])
+			case doubleB:
+				witness.text, witness.found = ant.segmentWitness(a[i])
+			default:
+				witness.text, witness.found = ant.segmentOverlap(a[i], b[j])
+			}
+			witnesses[key] = witness
+		}
+		if witness.found {
+			next := overlapState{i + 1, j + 1, true}
+			if doubleA {
+				next.i = i
+			}
+			if doubleB {
+				next.j = j
+			}
+			push(next, state, witness.text, true)
+		}
+	}
+	return nil, false, false
+}
+
+// chooseTrailing 为示例路径选择结尾分隔符；没有任何段的路径只能是""或者单独的分隔符
+func chooseTrailing(leading, nonEmpty bool, trailing []bool) (bool, bool) {
+	if !nonEmpty {
+		return leading, containsBool(trailing, leading)
+	}
+	return !containsBool(trailing, false), true
+}
+
+// buildPath 由段拼出完整的示例路径
+func (ant *AntPathMatcher) buildPath(leading bool, segments []string, trailing bool) string {
+	builder := strings.Builder{}
+	if leading {
+		builder.WriteString(ant.pathSeparator)
+	}
+	builder.WriteString(strings.Join(segments, ant.pathSeparator))
+	if trailing && len(segments) > 0 {
+		builder.WriteString(ant.pathSeparator)
+	}
+	return builder.String()
+}
+
+// segmentCovers 判断模式段x是否匹配模式段y能匹配的每一个非空字符串
+func (ant *AntPathMatcher) segmentCovers(x, y *patternSegment) bool {
+	if x.text == y.text || (!ant.caseSensitive && strings.EqualFold(x.text, y.text)) {
+		return true
+	}
+	if y.kind == LiteralSegment {
+		return ant.matchStrings(x.text, y.text, nil)
+	}
+	if x.hasRegex() {
+		return false
+	}
+	return ant.tokensCover(x.tokens, y.tokens)
+}
+
+// tokensCover 通过对x做子集构造，在y上搜索一个x不匹配的反例；y中的正则变量按"*"放宽处理
+func (ant *AntPathMatcher) tokensCover(x, y []segmentToken) bool {
+	alphabet := ant.alphabet(x, y)
+	n, m := len(x), len(y)
+	closure := func(set []bool) string {
+		for i := 0; i < n; i++ {
+			if set[i] && x[i].kind == starToken {
+				set[i+1] = true
+			}
+		}
+		key := make([]byte, n+1)
+		for i, in := range set {
+			if in {
+				key[i] = 1
+			}
+		}
+		return string(key)
+	}
+	step := func(key string, c rune) string {
+		set := make([]bool, n+1)
+		for i := 0; i < n; i++ {
+			if key[i] == 0 {
+				continue
+			}
+			switch x[i].kind {
+			case literalToken:
+				if ant.foldRune(x[i].char) == c {
+					set[i+1] = true
+				}
+			case anyToken:
+				set[i+1] = true
+			case starToken:
+				set[i] = true
+			}
+		}
+		return closure(set)
+	}
+
+	type coverState struct {
+		j        int
+		set      string
+		nonEmpty bool
+	}
+	initial := make([]bool, n+1)
+	initial[0] = true
+	start := coverState{0, closure(initial), false}
+	visited := map[coverState]bool{start: true}
+	queue := []coverState{start}
+	push := func(next coverState) {
+		if !visited[next] {
+			visited[next] = true
+			queue = append(queue, next)
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		if state.j == m {
+			if state.nonEmpty && state.set[n] == 0 {
+				return false
+			}
+			continue
+		}
+		switch y[state.j].kind {
+		case literalToken:
+			push(coverState{state.j + 1, step(state.set, ant.foldRune(y[state.j].char)), true})
+		case anyToken:
+			for _, c := range alphabet {
+				push(coverState{state.j + 1, step(state.set, c), true})
+			}
+		default:
+			push(coverState{state.j + 1, state.set, state.nonEmpty})
+			for _, c := range alphabet {
+				push(coverState{state.j, step(state.set, c), true})
+			}
+		}
+	}
+	return true
+}
+
+// segmentOverlap 寻找一个能同时被模式段x与y匹配的非空字符串
+func (ant *AntPathMatcher) segmentOverlap(x, y *patternSegment) (string, bool) {
+	if x.kind == LiteralSegment {
+		return x.text, ant.matchStrings(y.text, x.text, nil)
+	}
+	if y.kind == LiteralSegment {
+		return y.text, ant.matchStrings(x.text, y.text, nil)
+	}
+	free := []rune(ant.freeSegment())
+	candidates := make([]string, 0)
+	if x.hasRegex() || y.hasRegex() {
+		// 正则变量按"*"放宽后得到的示例未必满足约束，改用正则样例中的字符填充通配符再试
+		samples := []string{ant.segmentSample(x), ant.segmentSample(y)}
+		candidates = append(candidates, samples...)
+		for _, sample := range samples {
+			free = append(free, []rune(sample)...)
+		}
+	}
+	for _, c := range free {
+		if witness, ok := ant.tokensOverlap(x.tokens, y.tokens, c); ok {
+			candidates = append(candidates, witness)
+		}
+	}
+	for _, candidate := range candidates {
+		if candidate != "" && ant.matchStrings(x.text, candidate, nil) && ant.matchStrings(y.text, candidate, nil) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// segmentWitness 返回模式段x能匹配的一个非空字符串
+func (ant *AntPathMatcher) segmentWitness(x *patternSegment) (string, bool) {
+	return ant.segmentOverlap(x, parseSegment("*"))
+}
+
+// tokensOverlap 在两个段的元素序列的乘积上做广度优先搜索，通配符处填入free；正则变量按"*"放宽处理，结果需要再次验证
+func (ant *AntPathMatcher) tokensOverlap(x, y []segmentToken, free rune) (string, bool) {
+	n, m := len(x), len(y)
+	start := overlapState{}
+	traces := map[overlapState]overlapTrace{start: {}}
+	queue := []overlapState{start}
+	push := func(next, prev overlapState, emitted string, consumed bool) {
+		if _, ok := traces[next]; !ok {
+			traces[next] = overlapTrace{prev: prev, emitted: emitted, consumed: consumed}
+			queue = append(queue, next)
+		}
+	}
+	isStar := func(token segmentToken) bool {
+		return token.kind == starToken || token.kind == regexToken
+	}
+
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		i, j := state.i, state.j
+		if i == n && j == m && state.nonEmpty {
+			builder := make([]string, 0)
+			for current := state; current != start; current = traces[current].prev {
+				if trace := traces[current]; trace.consumed {
+					builder = append([]string{trace.emitted}, builder...)
+				}
+			}
+			return strings.Join(builder, ""), true
+		}
+		if i < n && isStar(x[i]) {
+			push(overlapState{i + 1, j, state.nonEmpty}, state, "", false)
+		}
+		if j < m && isStar(y[j]) {
+			push(overlapState{i, j + 1, state.nonEmpty}, state, "", false)
+		}
+		if i == n || j == m {
+			continue
+		}
+		c := free
+		switch {
+		case x[i].kind == literalToken && y[j].kind == literalToken:
+			if ant.foldRune(x[i].char) != ant.foldRune(y[j].char) {
+				continue
+			}
+			c = x[i].char
+		case x[i].kind == literalToken:
+			c = x[i].char
+		case y[j].kind == literalToken:
+			c = y[j].char
+		}
+		next := overlapState{i + 1, j + 1, true}
+		if isStar(x[i]) {
+			next.i = i
+		}
+		if isStar(y[j]) {
+			next.j = j
+		}
+		push(next, state, string(c), true)
+	}
+	return "", false
+}
+
+// segmentSample 构造一个模式段能匹配的字符串，正则变量使用regexSample给出的样例
+func (ant *AntPathMatcher) segmentSample(x *patternSegment) string {
+	builder := strings.Builder{}
+	for _, token := range x.tokens {
+		switch token.kind {
+		case literalToken:
+			builder.WriteRune(token.char)
+		case anyToken:
+			builder.WriteString(ant.freeSegment())
+		case regexToken:
+			builder.WriteString(regexSample(token.regex))
+		}
+	}
+	return builder.String()
+}
+
+// regexSample 构造一个能被给定正则表达式匹配的简短字符串，无法解析时返回空字符串
+func regexSample(expr string) string {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return ""
+	}
+	builder := strings.Builder{}
+	var walk func(re *syntax.Regexp)
+	walk = func(re *syntax.Regexp) {
+		switch re.Op {
+		case syntax.OpLiteral:
+			builder.WriteString(string(re.Rune))
+		case syntax.OpCharClass:
+			if len(re.Rune) > 0 {
+				builder.WriteRune(re.Rune[0])
+			}
+		case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+			builder.WriteRune('x')
+		case syntax.OpCapture, syntax.OpPlus, syntax.OpAlternate:
+			walk(re.Sub[0])
+		case syntax.OpRepeat:
+			for i := 0; i < re.Min; i++ {
+				walk(re.Sub[0])
+			}
+		case syntax.OpConcat:
+			for _, sub := range re.Sub {
+				walk(sub)
+			}
+		}
+	}
+	walk(re)
+	return builder.String()
+}
+
+// alphabet 返回搜索时需要区分的字符：两个段中出现的字面量字符，外加一个不属于它们的字符
+func (ant *AntPathMatcher) alphabet(x, y []segmentToken) []rune {
+	seen := make(map[rune]bool)
+	alphabet := make([]rune, 0)
+	for _, tokens := range [][]segmentToken{x, y} {
+		for _, token := range tokens {
+			if token.kind != literalToken {
+				continue
+			}
+			if c := ant.foldRune(token.char); !seen[c] {
+				seen[c] = true
+				alphabet = append(alphabet, c)
+			}
+		}
+	}
+	for _, c := range freeChars {
+		if !seen[c] {
+			return append(alphabet, c)
+		}
+	}
+	return alphabet
+}
+
+// freeSegment 返回一个不含路径分隔符的单字符段，用作通配符的示例
+func (ant *AntPathMatcher) freeSegment() string {
+	for _, c := range freeChars {
+		if !strings.ContainsRune(ant.pathSeparator, c) {
+			return string(c)
+		}
+	}
+	return "x"
+}
+
+// foldRune 不区分大小写时将字符转为小写
+func (ant *AntPathMatcher) foldRune(c rune) rune {
+	if ant.caseSensitive {
+		return c
+	}
+	return unicode.ToLower(c)
+}
+
+// containsBool
+func containsBool(values []bool, value bool) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package antstyle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCovers(t *testing.T) {
+	cases := []struct {
+		a    string
+		b    string
+		want bool
+	}{
+		{"/a/b", "/a/b", true},
+		{"/a/*", "/a/b", true},
+		{"/a/b", "/a/*", false},
+		{"/a/*", "/a/{id}", true},
+		{"/a/{id}", "/a/*", false}, // "/a/*"还匹配"/a/"
+		{"/a/*", "/a/*.html", true},
+		{"/a/*.html", "/a/*", false},
+		{"/a/?", "/a/b", true},
+		{"/a/?", "/a/*", false},
+		{"/**", "/a/**/b", true},
+		{"/a/**", "/a/b/**", true},
+		{"/a/b/**", "/a/**", false},
+		{"/a/**", "/a", true},
+		{"/a/**", "/a/*", true},
+		{"/a/*", "/a/**", false},
+		{"/**/b", "/a/**/b", true},
+		{"/a/**/b", "/**/b", false},
+		{"/*/**", "/**/*", true},
+		{"/a/*", "/a/", true},
+		{"/a/b", "/a/b/", false},
+		{"/a/**/", "/a/**", true},
+		{"a/**", "/a/**", false},
+		{"/a/{id}", "/a/{id:\\d+}", true},
+		{"/a/{id:\\d+}", "/a/{id}", false},
+		{"/a/{id:\\d+}", "/a/{id:\\d+}", true},
+		{"/a/*", "/a/{id:[a-z]+}", true},
+		{"/**/{id:\\d+}", "/x/{id:\\d+}", true},
+	}
+	ant := New()
+	for _, c := range cases {
+		if got := ant.Covers(c.a, c.b); got != c.want {
+			t.Errorf("Covers(%q, %q) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	cases := []struct {
+		a    string
+		b    string
+		want bool
+	}{
+		{"/a/b", "/a/b", true},
+		{"/a/b", "/a/c", false},
+		{"/a/*", "/*/b", true},
+		{"/a/*.html", "/a/*.json", false},
+		{"/a/x*", "/a/*y", true},
+		{"/a/?", "/a/bc", false},
+		{"/**/b", "/a/**", true},
+		{"/a/**", "/b/**", false},
+		{"/**/a/**", "/**/b/**", true},
+		{"/a/**/c", "/a/b", false},
+		{"/a/*", "/a/", true},
+		{"/a/b", "/a/b/", false},
+		{"/a", "a", false},
+		{"/a/{id:\\d+}", "/a/{name:[a-z]+}", false},
+		{"/a/{id:\\d+}", "/a/1*", true},
+		{"/**/{id:\\d+}.json", "/x/*.json", true},
+	}
+	ant := New()
+	for _, c := range cases {
+		got, example := ant.Overlaps(c.a, c.b)
+		if got != c.want {
+			t.Errorf("Overlaps(%q, %q) = %v, want %v", c.a, c.b, got, c.want)
+			continue
+		}
+		if got && (!ant.Match(c.a, example) || !ant.Match(c.b, example)) {
+			t.Errorf("Overlaps(%q, %q) example %q is not matched by both", c.a, c.b, example)
+		}
+		if reverse, _ := ant.Overlaps(c.b, c.a); reverse != got {
+			t.Errorf("Overlaps(%q, %q) = %v but reversed = %v", c.a, c.b, got, reverse)
+		}
+	}
+}
+
+// analysisPatterns 与Match对照检查Covers与Overlaps时使用的模式
+var analysisPatterns = []string{
+	"/a", "/a/", "/*", "/*/", "/a/*", "/*/b", "/a/b", "/**", "/a/**", "/**/b", "/a/**/b",
+	"/*/**", "/**/*", "/{x}", "/{x:a|b}", "/a*", "/?", "/a/**/", "a/*",
+}
+
+// analysisPaths 对照检查时枚举的路径，覆盖了上面模式的所有区分情况
+func analysisPaths() []string {
+	segments := []string{"a", "b", "ab", "c"}
+	paths := []string{"", "/", "a", "a/b"}
+	var build func(prefix string, depth int)
+	build = func(prefix string, depth int) {
+		if depth == 0 {
+			return
+		}
+		for _, segment := range segments {
+			path := prefix + "/" + segment
+			paths = append(paths, path, path+"/")
+			build(path, depth-1)
+		}
+	}
+	build("", 3)
+	return paths
+}
+
+func TestCoversAndOverlapsAgreeWithMatch(t *testing.T) {
+	ant := New()
+	paths := analysisPaths()
+	for _, a := range analysisPatterns {
+		for _, b := range analysisPatterns {
+			covered, overlapping := true, false
+			for _, path := range paths {
+				matchA, matchB := ant.Match(a, path), ant.Match(b, path)
+				if matchB && !matchA {
+					covered = false
+				}
+				if matchA && matchB {
+					overlapping = true
+				}
+			}
+			// 带正则约束时Covers是保守的，只要求返回true时成立
+			got := ant.Covers(a, b)
+			if got != covered && (got || !strings.Contains(a+b, ":")) {
+				t.Errorf("Covers(%q, %q) = %v, enumeration says %v", a, b, got, covered)
+			}
+			if got, _ := ant.Overlaps(a, b); got != overlapping {
+				t.Errorf("Overlaps(%q, %q) = %v, enumeration says %v", a, b, got, overlapping)
+			}
+		}
+	}
+}
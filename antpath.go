@@ -1,8 +1,11 @@
 package antstyle
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
+	"sync"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/aluka-7/utils"
@@ -26,13 +29,18 @@ func init() {
 // AntPathMatcher 实现了接口 PathMatcher
 type AntPathMatcher struct {
 	pathSeparator         string
-	tokenizedPatternCache *utils.SyncMap // 标记化模式缓存（线程安全）
-	stringMatcherCache    *utils.SyncMap // 字符串匹配器缓存（线程安全）
+	tokenizedPatternCache PatternCache // 标记化模式缓存（线程安全）
+	stringMatcherCache    PatternCache // 字符串匹配器缓存（线程安全）
+	defaultCaches         bool         // 是否使用自己创建的默认缓存，只有默认缓存会在模式过多时关闭
 
 	pathSeparatorPatternCache *PathSeparatorPatternCache
-	caseSensitive             bool // 区分大小写,默认值为true
-	trimTokens                bool // 默认值为false
-	cachePatterns             bool // 默认值为true
+	caseSensitive             bool   // 区分大小写,默认值为true
+	trimTokens                bool   // 默认值为false
+	cachePatterns             bool   // 默认值为true
+	limits                    Limits // 复杂度上限,默认不限制
+
+	resultCache      *resultCache // 匹配结果缓存，默认关闭
+	resultCacheMutex sync.RWMutex
 }
 
 func New() *AntPathMatcher {
@@ -40,14 +48,27 @@ func New() *AntPathMatcher {
 	return ant
 }
 func NewS(separator string) *AntPathMatcher {
+	ant := NewWithCache(separator, NewMapPatternCache(), NewMapPatternCache())
+	ant.defaultCaches = true
+	return ant
+}
+
+// NewWithCache 使用给定的缓存创建AntPathMatcher
+/**
+ *tokenizedPatternCache保存整个模式的预处理结果，stringMatcherCache保存单个模式段的匹配器，
+ *两者不能是同一个缓存实例，但可以在多个AntPathMatcher之间共享。
+ *传入NoopPatternCache{}可以单独关闭其中一个缓存。
+ *与默认缓存不同，传入的缓存在模式过多时不会被自动清空和关闭，其大小由缓存自己控制。
+ */
+func NewWithCache(separator string, tokenizedPatternCache, stringMatcherCache PatternCache) *AntPathMatcher {
 	if strings.EqualFold(utils.EmptyString, separator) {
 		separator = DefaultPathSeparator
 	}
 	ant := &AntPathMatcher{}
 	//
 	ant.pathSeparator = separator
-	ant.tokenizedPatternCache = new(utils.SyncMap)
-	ant.stringMatcherCache = new(utils.SyncMap)
+	ant.tokenizedPatternCache = tokenizedPatternCache
+	ant.stringMatcherCache = stringMatcherCache
 	ant.pathSeparatorPatternCache = NewDefaultPathSeparatorPatternCache(separator)
 
 	// filed
@@ -98,14 +119,28 @@ func (ant *AntPathMatcher) ExtractPathWithinPattern(pattern, path string) string
 	return builder
 }
 
+// TryMatch 与Match相同，但模式无效或模式、路径超出Limits时返回错误
+func (ant *AntPathMatcher) TryMatch(pattern, path string) (bool, error) {
+	return ant.cachedMatch(pattern, path, true, nil)
+}
+
+// ValidatePattern 检查模式中的正则约束能否编译以及模式是否超出Limits，通过检查的模式会被编译并缓存
+func (ant *AntPathMatcher) ValidatePattern(pattern string) error {
+	return ant.compilePattern(pattern).err
+}
+
 // @Override
 // ExtractUriTemplateVariables
 func (ant *AntPathMatcher) ExtractUriTemplateVariables(pattern, path string) *map[string]string {
-	variables := make(map[string]string)
-	result := ant.doMatch(pattern, path, true, &variables)
+	params := make(Params, 0)
+	result, err := ant.cachedMatch(pattern, path, true, &params)
+	if err != nil {
+		panic(err.Error())
+	}
 	if !result {
 		panic("Pattern \"" + pattern + "\" is not a match for \"" + path + "\"")
 	}
+	variables := params.ToMap()
 	return &variables
 }
 
@@ -116,70 +151,104 @@ func (ant *AntPathMatcher) GetPatternComparator(path string) *AntPatternComparat
 }
 
 // @Override
-// Combine 将pattern1和pattern2联合成一个新的pattern
+// Combine 将pattern1和pattern2联合成一个新的pattern，扩展名冲突时panic，需要错误时使用TryCombine
 func (ant *AntPathMatcher) Combine(pattern1, pattern2 string) string {
+	combined, err := ant.combine(pattern1, pattern2)
+	if err != nil {
+		panic("Cannot combine patterns: " + pattern1 + " vs " + pattern2)
+	}
+	return combined
+}
+
+// TryCombine 与Combine相同，但在扩展名冲突或合并后的模式重复声明同一个变量时返回错误
+/**
+ *例如"/*.html"与"/*.txt"的扩展名冲突，"/{id}"与"/{id}"合并后为"/{id}/{id}"，变量id被声明了两次。
+ */
+func (ant *AntPathMatcher) TryCombine(pattern1, pattern2 string) (string, error) {
+	combined, err := ant.combine(pattern1, pattern2)
+	if err != nil {
+		return "", err
+	}
+	seen := make(map[string]bool)
+	for _, segment := range strings.Split(combined, ant.pathSeparator) {
+		for _, token := range parseSegment(segment).tokens {
+			if token.name == "" {
+				continue
+			}
+			if seen[token.name] {
+				return "", fmt.Errorf("antstyle: combining %q and %q declares URI variable %q more than once", pattern1, pattern2, token.name)
+			}
+			seen[token.name] = true
+		}
+	}
+	return combined, nil
+}
+
+// combine 按Spring的AntPathMatcher.combine合并两个模式，分隔符可以是任意字符串
+func (ant *AntPathMatcher) combine(pattern1, pattern2 string) (string, error) {
 	if !utils.HasText(pattern1) && !utils.HasText(pattern2) {
-		return ""
+		return "", nil
 	}
 	if !utils.HasText(pattern1) {
-		return pattern2
+		return pattern2, nil
 	}
 	if !utils.HasText(pattern2) {
-		return pattern1
+		return pattern1, nil
 	}
-	// 处理pattern
-	pattern1ContainsUriVar := strings.Index(pattern1, "{") != -1
-	if !strings.EqualFold(pattern1, pattern2) && !pattern1ContainsUriVar && ant.Match(pattern1, pattern2) {
+	pattern1ContainsUriVar := strings.Contains(pattern1, "{")
+	if pattern1 != pattern2 && !pattern1ContainsUriVar && ant.Match(pattern1, pattern2) {
 		// /* + /hotel -> /hotel ; "/*.*" + "/*.html" -> /*.html
 		// However /user + /user -> /usr/user ; /{foo} + /bar -> /{foo}/bar
-		return pattern2
+		return pattern2, nil
 	}
 	// /hotels/* + /booking -> /hotels/booking
 	// /hotels/* + booking -> /hotels/booking
-	if strings.HasSuffix(pattern1, ant.pathSeparatorPatternCache.GetEndsOnWildCard()) {
-		return ant.concat(pattern1[0:len(pattern1)-2], pattern2)
+	if endsOnWildCard := ant.pathSeparatorPatternCache.GetEndsOnWildCard(); strings.HasSuffix(pattern1, endsOnWildCard) {
+		return ant.concat(pattern1[:len(pattern1)-len(endsOnWildCard)], pattern2), nil
 	}
-
 	// /hotels/** + /booking -> /hotels/**/booking
 	// /hotels/** + booking -> /hotels/**/booking
 	if strings.HasSuffix(pattern1, ant.pathSeparatorPatternCache.GetEndsOnDoubleWildCard()) {
-		return ant.concat(pattern1, pattern2)
-	}
-
-	starDotPos1 := strings.Index(pattern1, "*.")
-	if pattern1ContainsUriVar || starDotPos1 == -1 || strings.EqualFold(".", ant.pathSeparator) {
-		// simply concatenate the two patterns
-		return ant.concat(pattern1, pattern2)
-	}
-
-	ext1 := pattern1[starDotPos1+1:]
-	dotPos2 := strings.Index(pattern2, ".")
-	file2 := utils.EmptyString
-	ext2 := utils.EmptyString
-	if dotPos2 == -1 {
-		file2 = pattern2
-		ext2 = ""
-	} else {
-		file2 = pattern2[0:dotPos2]
-		ext2 = pattern2[dotPos2:]
-	}
-	ext1All := strings.EqualFold(".*", ext1) || strings.EqualFold(utils.EmptyString, ext1)
-	ext2All := strings.EqualFold(".*", ext2) || strings.EqualFold(utils.EmptyString, ext2)
+		return ant.concat(pattern1, pattern2), nil
+	}
+	// 分隔符中含有"."时无法区分扩展名
+	if pattern1ContainsUriVar || strings.Contains(ant.pathSeparator, ".") {
+		return ant.concat(pattern1, pattern2), nil
+	}
+	// 扩展名只在最后一段中查找：/*.html + /hotel -> /hotel.html
+	_, file1 := ant.splitLastSegment(pattern1)
+	starDotPos1 := strings.Index(file1, "*.")
+	if starDotPos1 == -1 {
+		return ant.concat(pattern1, pattern2), nil
+	}
+	ext1 := file1[starDotPos1+1:]
+	dir2, file2 := ant.splitLastSegment(pattern2)
+	ext2 := ""
+	if dotPos2 := strings.Index(file2, "."); dotPos2 != -1 {
+		file2, ext2 = file2[:dotPos2], file2[dotPos2:]
+	}
+	ext1All := ext1 == ".*" || ext1 == ""
+	ext2All := ext2 == ".*" || ext2 == ""
 	if !ext1All && !ext2All {
-		panic("Cannot combine patterns: " + pattern1 + " vs " + pattern2)
+		return "", fmt.Errorf("antstyle: cannot combine patterns %q and %q: conflicting extensions %q and %q", pattern1, pattern2, ext1, ext2)
 	}
-	//
-	ext := utils.EmptyString
 	if ext1All {
-		ext = ext2
-	} else {
-		ext = ext1
+		return dir2 + file2 + ext2, nil
+	}
+	return dir2 + file2 + ext1, nil
+}
+
+// splitLastSegment 在最后一个分隔符之后拆分模式，返回的目录部分包含该分隔符
+func (ant *AntPathMatcher) splitLastSegment(pattern string) (string, string) {
+	idx := strings.LastIndex(pattern, ant.pathSeparator)
+	if idx == -1 {
+		return "", pattern
 	}
-	return file2 + ext
+	return pattern[:idx+len(ant.pathSeparator)], pattern[idx+len(ant.pathSeparator):]
 }
 
 func (ant *AntPathMatcher) PatternCacheSize() int64 {
-	return ant.stringMatcherCache.MyLen()
+	return int64(ant.stringMatcherCache.Len())
 }
 
 // SetPathSeparator The default is "/",as in ant.
@@ -190,6 +259,7 @@ func (ant *AntPathMatcher) SetPathSeparator(pathSeparator string) {
 	if !strings.EqualFold(utils.EmptyString, pathSeparator) {
 		ant.pathSeparator = pathSeparator
 		ant.pathSeparatorPatternCache = NewDefaultPathSeparatorPatternCache(pathSeparator)
+		ant.clearCaches()
 	}
 }
 
@@ -200,6 +270,7 @@ func (ant *AntPathMatcher) SetPathSeparator(pathSeparator string) {
  */
 func (ant *AntPathMatcher) SetCaseSensitive(caseSensitive bool) {
 	ant.caseSensitive = caseSensitive
+	ant.clearCaches()
 }
 
 // SetTrimTokens 是否去除空格 The default is false
@@ -208,6 +279,16 @@ func (ant *AntPathMatcher) SetCaseSensitive(caseSensitive bool) {
  */
 func (ant *AntPathMatcher) SetTrimTokens(trimTokens bool) {
 	ant.trimTokens = trimTokens
+	ant.clearCaches()
+}
+
+// SetLimits 设置处理不可信输入时的复杂度上限
+/**
+ *之后的匹配会按新的限制重新检查模式。
+ */
+func (ant *AntPathMatcher) SetLimits(limits Limits) {
+	ant.limits = limits
+	ant.clearCaches()
 }
 
 // SetCachePatterns
@@ -227,21 +308,51 @@ func (ant *AntPathMatcher) SetCachePatterns(cachePatterns bool) {
 
 /**
  *实际上将给定的{@code path}与给定的{@code pattern}相匹配。
+ *路径在栈上的缓冲区中原地切分，字面量段直接比较字符串，因此对不含变量的模式不会产生堆分配。
  *@param pattern要匹配的模式
  *@param path要测试的路径字符串
  *@param fullMatch是否需要完整的模式匹配（否则为模式匹配只要给定的基本路径就足够了）
  *@return {@code true}（如果提供的{@code path}匹配，{@ code false}，如果不匹配）
  */
-func (ant *AntPathMatcher) doMatch(pattern, path string, fullMatch bool, uriTemplateVariables *map[string]string) bool {
-	if strings.HasPrefix(path, ant.pathSeparator) != strings.HasPrefix(pattern, ant.pathSeparator) {
-		return false
+func (ant *AntPathMatcher) doMatch(pattern, path string, fullMatch bool, params *Params) bool {
+	matched, _ := ant.cachedMatch(pattern, path, fullMatch, params)
+	return matched
+}
+
+// tryMatch 与doMatch相同，模式或路径超出Limits时返回*LimitError
+func (ant *AntPathMatcher) tryMatch(pattern, path string, fullMatch bool, params *Params) (bool, error) {
+	if err := checkLimit("MaxPathLength", len(path), ant.limits.MaxPathLength, path); err != nil {
+		return false, err
 	}
-	pattDirs := ant.tokenizePattern(pattern)
-	if fullMatch && ant.caseSensitive && !ant.isPotentialMatch(path, pattDirs) {
-		return false
+	compiled := ant.compilePattern(pattern)
+	if compiled.err != nil {
+		return false, compiled.err
+	}
+	if strings.HasPrefix(path, ant.pathSeparator) != compiled.leading {
+		return false, nil
+	}
+	var buf [pathSegmentBufferSize]string
+	var pathDirs []string
+	if ant.limits.MaxSegments > 0 {
+		pathDirs = ant.splitPath(path, buf[:0])
+		if err := checkLimit("MaxSegments", len(pathDirs), ant.limits.MaxSegments, path); err != nil {
+			return false, err
+		}
+	}
+	if fullMatch {
+		if matched, decided := ant.matchShape(compiled, path); decided {
+			return matched, nil
+		}
 	}
+	if pathDirs == nil {
+		pathDirs = ant.splitPath(path, buf[:0])
+	}
+	return ant.matchSegments(compiled, pathDirs, strings.HasSuffix(path, ant.pathSeparator), fullMatch, params), nil
+}
 
-	pathDirs := ant.tokenizePath(path)
+// matchSegments 将已切分的路径段与预处理后的模式进行匹配
+func (ant *AntPathMatcher) matchSegments(compiled *compiledPattern, pathDirs []string, pathTrailing, fullMatch bool, params *Params) bool {
+	pattDirs := compiled.segments
 	// define variable
 	pattIdxStart := 0
 	pattIdxEnd := len(pattDirs) - 1
@@ -249,36 +360,31 @@ func (ant *AntPathMatcher) doMatch(pattern, path string, fullMatch bool, uriTemp
 	pathIdxEnd := len(pathDirs) - 1
 
 	// Match all elements up to the first **
-	for {
-		if pattIdxStart <= pattIdxEnd && pathIdxStart <= pathIdxEnd {
-			pattDir := pattDirs[pattIdxStart]
-			if strings.EqualFold("**", *pattDir) {
-				break
-			}
-			if !ant.matchStrings(*pattDir, *pathDirs[pathIdxStart], uriTemplateVariables) {
-				return false
-			}
-			pattIdxStart++
-			pathIdxStart++
-		} else {
-			// jump out of
+	for pattIdxStart <= pattIdxEnd && pathIdxStart <= pathIdxEnd {
+		pattDir := &pattDirs[pattIdxStart]
+		if pattDir.isDoubleWildcard() {
 			break
 		}
+		if !pattDir.match(pathDirs[pathIdxStart], ant.caseSensitive, params) {
+			return false
+		}
+		pattIdxStart++
+		pathIdxStart++
 	}
 
 	if pathIdxStart > pathIdxEnd {
 		// Path is exhausted, only match if rest of pattern is * or **'s
 		if pattIdxStart > pattIdxEnd {
-			return strings.HasSuffix(pattern, ant.pathSeparator) == strings.HasSuffix(path, ant.pathSeparator)
+			return compiled.trailing == pathTrailing
 		}
 		if !fullMatch {
 			return true
 		}
-		if pattIdxStart == pattIdxEnd && strings.EqualFold("*", *pattDirs[pattIdxStart]) && strings.HasSuffix(path, ant.pathSeparator) {
+		if pattIdxStart == pattIdxEnd && pattDirs[pattIdxStart].text == "*" && pathTrailing {
 			return true
 		}
 		for i := pattIdxStart; i <= pattIdxEnd; i++ {
-			if !strings.EqualFold("**", *pattDirs[i]) {
+			if !pattDirs[i].isDoubleWildcard() {
 				return false
 			}
 		}
@@ -286,177 +392,165 @@ func (ant *AntPathMatcher) doMatch(pattern, path string, fullMatch bool, uriTemp
 	} else if pattIdxStart > pattIdxEnd {
 		// String not exhausted, but pattern is. Failure.
 		return false
-	} else if !fullMatch && strings.EqualFold("**", *pattDirs[pattIdxStart]) {
+	} else if !fullMatch && pattDirs[pattIdxStart].isDoubleWildcard() {
 		// Path start definitely matches due to "**" part in pattern.
 		return true
 	}
 
 	// up to last '**'
-	for {
-		if pattIdxStart <= pattIdxEnd && pathIdxStart <= pathIdxEnd {
-			pattDir := pattDirs[pattIdxEnd]
-			if strings.EqualFold("**", *pattDir) {
-				break
-			}
-			if !ant.matchStrings(*pattDir, *pathDirs[pathIdxEnd], uriTemplateVariables) {
-				return false
-			}
-			pattIdxEnd--
-			pathIdxEnd--
-		} else {
+	// 从后向前只判断是否匹配，变量在确认整体匹配后再按模式中的顺序提取
+	for pattIdxStart <= pattIdxEnd && pathIdxStart <= pathIdxEnd {
+		pattDir := &pattDirs[pattIdxEnd]
+		if pattDir.isDoubleWildcard() {
 			break
 		}
+		if !pattDir.match(pathDirs[pathIdxEnd], ant.caseSensitive, nil) {
+			return false
+		}
+		pattIdxEnd--
+		pathIdxEnd--
 	}
+	tailPatt, tailPath := pattDirs[pattIdxEnd+1:], pathDirs[pathIdxEnd+1:]
 	if pathIdxStart > pathIdxEnd {
 		// String is exhausted
 		for i := pattIdxStart; i <= pattIdxEnd; i++ {
-			if !strings.EqualFold("**", *pattDirs[i]) {
+			if !pattDirs[i].isDoubleWildcard() {
 				return false
 			}
 		}
+		return ant.captureSegments(tailPatt, tailPath, params)
+	}
+
+	// 剩余的模式段首尾都是"**"，在其上模拟NFA判断是否匹配
+	if !ant.matchBetweenDoubleWildcards(pattDirs[pattIdxStart:pattIdxEnd+1], pathDirs[pathIdxStart:pathIdxEnd+1]) {
+		return false
+	}
+	if params == nil {
 		return true
 	}
 
-	for {
-		if pattIdxStart != pattIdxEnd && pathIdxStart <= pathIdxEnd {
-			patIdxTmp := -1
-			for i := pattIdxStart + 1; i <= pattIdxEnd; i++ {
-				if strings.EqualFold("**", *pattDirs[i]) {
-					patIdxTmp = i
-					break
-				}
-			}
-			if patIdxTmp == pattIdxStart+1 {
-				// '**/**' situation, so skip one
-				pattIdxStart++
-				continue
-			}
-			// Find the pattern between padIdxStart & padIdxTmp in str between
-			// strIdxStart & strIdxEnd
-			patLength := patIdxTmp - pattIdxStart - 1
-			strLength := pathIdxEnd - pathIdxStart + 1
-			foundIdx := -1
-
-		strLoop:
-			for i := 0; i <= strLength-patLength; i++ {
-				for j := 0; j < patLength; j++ {
-					subPat := pattDirs[pattIdxStart+j+1]
-					subStr := pathDirs[pathIdxStart+i+j]
-					if !ant.matchStrings(*subPat, *subStr, uriTemplateVariables) {
-						continue strLoop
-					}
-				}
-				foundIdx = pathIdxStart + i
+	// 已确认匹配，按原有的策略为"**"之间的每组段取最左侧的位置以提取变量
+	for pattIdxStart != pattIdxEnd && pathIdxStart <= pathIdxEnd {
+		patIdxTmp := -1
+		for i := pattIdxStart + 1; i <= pattIdxEnd; i++ {
+			if pattDirs[i].isDoubleWildcard() {
+				patIdxTmp = i
 				break
 			}
-
-			if foundIdx == -1 {
-				return false
+		}
+		if patIdxTmp == pattIdxStart+1 {
+			// '**/**' situation, so skip one
+			pattIdxStart++
+			continue
+		}
+		// Find the pattern between padIdxStart & padIdxTmp in str between
+		// strIdxStart & strIdxEnd
+		patLength := patIdxTmp - pattIdxStart - 1
+		strLength := pathIdxEnd - pathIdxStart + 1
+		foundIdx := -1
+		mark := len(*params)
+
+	strLoop:
+		for i := 0; i <= strLength-patLength; i++ {
+			for j := 0; j < patLength; j++ {
+				subPat := &pattDirs[pattIdxStart+j+1]
+				subStr := pathDirs[pathIdxStart+i+j]
+				if !subPat.match(subStr, ant.caseSensitive, params) {
+					// 丢弃这次尝试中已提取的变量
+					*params = (*params)[:mark]
+					continue strLoop
+				}
 			}
-
-			pattIdxStart = patIdxTmp
-			pathIdxStart = foundIdx + patLength
-		} else {
+			foundIdx = pathIdxStart + i
 			break
 		}
-	}
 
-	for i := pattIdxStart; i <= pattIdxEnd; i++ {
-		if !strings.EqualFold("**", *pattDirs[i]) {
+		if foundIdx == -1 {
 			return false
 		}
+
+		pattIdxStart = patIdxTmp
+		pathIdxStart = foundIdx + patLength
 	}
-	return true
-}
 
-// tokenizePattern default use cache
-/**
- * Tokenize the given path pattern into parts, based on this matcher's settings.
- * <p>Performs caching based on {@link #setCachePatterns}, delegating to
- * {@link #tokenizePath(String)} for the actual tokenization algorithm.
- * @param pattern the pattern to tokenize
- * @return the tokenized pattern parts
- */
-func (ant *AntPathMatcher) tokenizePattern(pattern string) []*string {
-	tokenized := make([]*string, 0)
-	// The first step is to fetch from the cache map.
-	value, ok := ant.tokenizedPatternCache.MyLoad(pattern)
-	if ok {
-		tokenized = value.([]*string)
-	} else {
-		// No records was fetched from the cache map.
-		tokenized = ant.tokenizePath(pattern)
-		// add
-		if tokenized != nil {
-			ant.tokenizedPatternCache.MyStore(pattern, tokenized)
+	for i := pattIdxStart; i <= pattIdxEnd; i++ {
+		if !pattDirs[i].isDoubleWildcard() {
+			return false
 		}
 	}
-	return tokenized
-}
-
-// tokenizePath
-func (ant *AntPathMatcher) tokenizePath(path string) []*string {
-	return utils.TokenizeToStringArray(path, ant.pathSeparator, ant.trimTokens, true)
+	return ant.captureSegments(tailPatt, tailPath, params)
 }
 
-// isPotentialMatch
-func (ant *AntPathMatcher) isPotentialMatch(path string, pattDirs []*string) bool {
-	if !ant.trimTokens {
-		pos := 0
-		for _, pattDir := range pattDirs {
-			skipped := ant.skipSeparator(path, pos, ant.pathSeparator)
-			pos += skipped
-			skipped = ant.skipSegment(path, pos, *pattDir)
-			if skipped < utf8.RuneCountInString(*pattDir) {
-				tempPattDir := rune((*pattDir)[0])
-				return skipped > 0 || utf8.RuneCountInString(*pattDir) > 0 && ant.isWildcardChar(tempPattDir)
-			}
-			pos += skipped
+// captureSegments 按顺序将已确认匹配的模式段中的变量追加到params，params为nil时直接返回true
+func (ant *AntPathMatcher) captureSegments(pattDirs []compiledSegment, pathDirs []string, params *Params) bool {
+	if params == nil {
+		return true
+	}
+	for i := range pattDirs {
+		if !pattDirs[i].match(pathDirs[i], ant.caseSensitive, params) {
+			return false
 		}
 	}
 	return true
 }
 
-// skipSegment
-func (ant *AntPathMatcher) skipSegment(path string, pos int, prefix string) int {
-	skipped := 0
-	for i := 0; i < utf8.RuneCountInString(prefix); i++ {
-		c := rune(prefix[i])
-		if ant.isWildcardChar(c) {
-			return skipped
+// matchBetweenDoubleWildcards 判断首尾均为"**"的模式段能否匹配给定的路径段
+/**
+ *以模式段的位置作为NFA的状态，逐个读入路径段并维护当前可达的状态集合：
+ *"**"可以不消耗路径段进入下一个状态，也可以消耗一个路径段停留在原状态。
+ *每个模式段与每个路径段最多比较一次，时间复杂度为O(n·m)，不会因为路径过长而回溯。
+ */
+func (ant *AntPathMatcher) matchBetweenDoubleWildcards(pattDirs []compiledSegment, pathDirs []string) bool {
+	var currentBuf, nextBuf [nfaBufferWords]uint64
+	words := (len(pattDirs) + 1 + 63) / 64
+	var current, next []uint64
+	if words > nfaBufferWords {
+		current, next = make([]uint64, words), make([]uint64, words)
+	} else {
+		current, next = currentBuf[:words], nextBuf[:words]
+	}
+
+	current[0] = 1
+	nfaClosure(pattDirs, current)
+	for _, pathDir := range pathDirs {
+		active := false
+		for i := range next {
+			next[i] = 0
 		}
-		currPos := pos + skipped
-		if currPos >= utf8.RuneCountInString(path) {
-			return 0
+		for k := range pattDirs {
+			if current[k/64]&(1<<(k%64)) == 0 {
+				continue
+			}
+			if pattDirs[k].isDoubleWildcard() {
+				next[k/64] |= 1 << (k % 64)
+				active = true
+			} else if pattDirs[k].match(pathDir, ant.caseSensitive, nil) {
+				next[(k+1)/64] |= 1 << ((k + 1) % 64)
+				active = true
+			}
 		}
-		if c == rune(path[currPos]) {
-			skipped++
+		if !active {
+			return false
 		}
+		nfaClosure(pattDirs, next)
+		current, next = next, current
 	}
-	return skipped
+	accept := len(pattDirs)
+	return current[accept/64]&(1<<(accept%64)) != 0
 }
 
-// skipSeparator
-func (ant *AntPathMatcher) skipSeparator(path string, pos int, separator string) int {
-	skipped := 0
-	for {
-		if utils.StartsWith(path, separator, pos+skipped) {
-			skipped += utf8.RuneCountInString(separator)
-		} else {
-			break
+// nfaClosure 将"**"不消耗路径段即可到达的状态加入集合
+func nfaClosure(pattDirs []compiledSegment, states []uint64) {
+	for k := range pattDirs {
+		if states[k/64]&(1<<(k%64)) != 0 && pattDirs[k].isDoubleWildcard() {
+			states[(k+1)/64] |= 1 << ((k + 1) % 64)
 		}
 	}
-	return skipped
 }
 
-// isWildcardChar
-func (ant *AntPathMatcher) isWildcardChar(c rune) bool {
-	for _, candidate := range WildcardChars {
-		if c == candidate {
-			return true
-		}
-	}
-	return false
+// tokenizePath
+func (ant *AntPathMatcher) tokenizePath(path string) []*string {
+	return utils.TokenizeToStringArray(path, ant.pathSeparator, ant.trimTokens, true)
 }
 
 /**
@@ -482,15 +576,16 @@ func (ant *AntPathMatcher) matchStrings(pattern, str string, uriTemplateVariable
 func (ant *AntPathMatcher) getStringMatcher(pattern string) *AntPathStringMatcher {
 	var matcher *AntPathStringMatcher
 	cachePatterns := ant.cachePatterns
+	key := PatternKey{Pattern: pattern, CaseSensitive: ant.caseSensitive}
 	if cachePatterns {
-		value, ok := ant.stringMatcherCache.MyLoad(pattern)
+		value, ok := ant.stringMatcherCache.Get(key)
 		if ok && value != nil {
 			matcher = value.(*AntPathStringMatcher)
 		}
 	}
 	if matcher == nil {
 		matcher = NewMatchesStringMatcher(pattern, ant.caseSensitive)
-		if cachePatterns && ant.PatternCacheSize() >= CacheTurnoffThreshold {
+		if cachePatterns && ant.defaultCaches && ant.PatternCacheSize() >= CacheTurnoffThreshold {
 			// Try to adapt to the runtime situation that we're encountering:
 			// There are obviously too many different patterns coming in here...
 			// So let's turn off the cache since the patterns are unlikely to be reoccurring.
@@ -498,7 +593,7 @@ func (ant *AntPathMatcher) getStringMatcher(pattern string) *AntPathStringMatche
 			return matcher
 		}
 		if cachePatterns {
-			ant.stringMatcherCache.MyStore(pattern, matcher)
+			ant.stringMatcherCache.Put(key, matcher)
 		}
 	}
 	return matcher
@@ -510,7 +605,7 @@ func (ant *AntPathMatcher) concat(path1, path2 string) string {
 	path2StartsWithSeparator := strings.HasPrefix(path2, ant.pathSeparator)
 
 	if path1EndsWithSeparator && path2StartsWithSeparator {
-		return path1 + path2[1:]
+		return path1 + path2[len(ant.pathSeparator):]
 	} else if path1EndsWithSeparator || path2StartsWithSeparator {
 		return path1 + path2
 	} else {
@@ -518,11 +613,24 @@ func (ant *AntPathMatcher) concat(path1, path2 string) string {
 	}
 }
 
+// clearCaches 在配置改变后清除按旧配置缓存的内容
+/**
+ *缓存的键中已包含配置，旧的条目不会再被取到；这里只是释放它们占用的空间。
+ *通过NewWithCache传入的缓存可能被其他AntPathMatcher共享，因此不清除。
+ */
+func (ant *AntPathMatcher) clearCaches() {
+	if ant.defaultCaches {
+		ant.tokenizedPatternCache.Clear()
+		ant.stringMatcherCache.Clear()
+	}
+	ant.clearResultCache()
+}
+
 // deactivatePatternCache
 func (ant *AntPathMatcher) deactivatePatternCache() {
 	ant.cachePatterns = false
-	utils.ClearSyncMap(ant.tokenizedPatternCache)
-	utils.ClearSyncMap(ant.stringMatcherCache)
+	ant.tokenizedPatternCache.Clear()
+	ant.stringMatcherCache.Clear()
 }
 
 /**
@@ -557,8 +665,16 @@ func FindSubmatch
 type AntPathStringMatcher struct {
 	// variableNames
 	variableNames []*string
-	// pattern
-	pattern *regexp.Regexp
+	// pattern 由patternBuilder生成的正则表达式，完整匹配时在第一次需要时才编译
+	pattern     *regexp.Regexp
+	source      string
+	compileOnce sync.Once
+	err         error // 正则表达式无法编译时的错误，此时pattern为nil，不会匹配任何字符串
+
+	// tokens 不含正则约束的段使用的通配元素，为nil时使用正则表达式匹配
+	tokens []segmentToken
+	// captureIndex 唯一的变量在tokens中的下标，不能直接截取变量时为-1
+	captureIndex int
 
 	// caseSensitive 区分大小写
 	caseSensitive bool
@@ -569,34 +685,62 @@ type AntPathStringMatcher struct {
 
 // NewDefaultStringMatcher part match
 func NewDefaultStringMatcher(pattern string, caseSensitive bool) *AntPathStringMatcher {
-	stringMatcher := &AntPathStringMatcher{}
+	stringMatcher := &AntPathStringMatcher{captureIndex: -1}
 	stringMatcher.capturingGroupCount = 0
 	stringMatcher.variableNames = make([]*string, 0)
 	// caseSensitive
 	stringMatcher.caseSensitive = caseSensitive
 	// 写入表达式
-	reg, err := regexp.Compile(*stringMatcher.patternBuilder(pattern, false, caseSensitive))
-	if err == nil {
-		stringMatcher.pattern = reg
-	}
+	stringMatcher.source = *stringMatcher.patternBuilder(pattern, false, caseSensitive)
+	stringMatcher.compile()
 	return stringMatcher
 }
 
 // NewMatchesStringMatcher full match
+/**
+ *只含"*"、"?"与不带约束的"{name}"的段使用globMatch进行匹配，
+ *正则表达式只在提取变量时才编译；带有{name:regex}约束的段立即编译正则表达式。
+ */
 func NewMatchesStringMatcher(pattern string, caseSensitive bool) *AntPathStringMatcher {
-	stringMatcher := &AntPathStringMatcher{}
+	stringMatcher := &AntPathStringMatcher{captureIndex: -1}
 	stringMatcher.capturingGroupCount = 0
 	stringMatcher.variableNames = make([]*string, 0)
 	// caseSensitive
 	stringMatcher.caseSensitive = caseSensitive
 	// 写入表达式
-	reg, err := regexp.Compile(*stringMatcher.patternBuilder(pattern, true, caseSensitive))
-	if err == nil {
-		stringMatcher.pattern = reg
+	stringMatcher.source = *stringMatcher.patternBuilder(pattern, true, caseSensitive)
+	segment := parseSegment(pattern)
+	if segment.hasRegex() {
+		stringMatcher.compile()
+		return stringMatcher
+	}
+	stringMatcher.tokens = segment.tokens
+	stars := 0
+	for i := range stringMatcher.tokens {
+		if !caseSensitive {
+			stringMatcher.tokens[i].char = unicode.ToLower(stringMatcher.tokens[i].char)
+		}
+		if stringMatcher.tokens[i].kind == starToken {
+			stars++
+			if stringMatcher.tokens[i].name != "" {
+				stringMatcher.captureIndex = i
+			}
+		}
+	}
+	if stars != 1 || stringMatcher.GroupCount() != 1 {
+		stringMatcher.captureIndex = -1
 	}
 	return stringMatcher
 }
 
+// compile 编译正则表达式，只执行一次，无法编译时返回nil并记录错误
+func (sm *AntPathStringMatcher) compile() *regexp.Regexp {
+	sm.compileOnce.Do(func() {
+		sm.pattern, sm.err = regexp.Compile(sm.source)
+	})
+	return sm.pattern
+}
+
 /**
 * Main entry point.
 *
@@ -604,34 +748,124 @@ func NewMatchesStringMatcher(pattern string, caseSensitive bool) *AntPathStringM
  */
 // MatchStrings
 func (sm *AntPathStringMatcher) MatchStrings(str string, uriTemplateVariables *map[string]string) bool {
+	if uriTemplateVariables == nil || sm.GroupCount() == 0 {
+		return sm.Matches(str)
+	}
+	params := make(Params, 0, sm.GroupCount())
+	if !sm.matchParams(str, &params) {
+		return false
+	}
+	for _, p := range params {
+		(*uriTemplateVariables)[p.Key] = p.Value
+	}
+	return true
+}
+
+// matchParams 判断字符串是否匹配，并将变量按顺序追加到params
+/**
+ *只含一个变量且没有其他"*"的段（例如"{id}"或"{name}.html"）不使用正则表达式：
+ *变量前后的元素长度固定，匹配后直接截取中间的部分。
+ */
+func (sm *AntPathStringMatcher) matchParams(str string, params *Params) bool {
+	if sm.GroupCount() == 0 {
+		return sm.Matches(str)
+	}
+	if sm.captureIndex != -1 {
+		if !sm.Matches(str) {
+			return false
+		}
+		value := str
+		for i := 0; i < sm.captureIndex; i++ {
+			_, size := utf8.DecodeRuneInString(value)
+			value = value[size:]
+		}
+		for i := sm.captureIndex + 1; i < len(sm.tokens); i++ {
+			_, size := utf8.DecodeLastRuneInString(value)
+			value = value[:len(value)-size]
+		}
+		if !sm.caseSensitive {
+			value = strings.ToLower(value)
+		}
+		*params = append(*params, Param{Key: *sm.variableNames[0], Value: value})
+		return true
+	}
 	// 区分大小写
 	if !sm.caseSensitive {
 		str = strings.ToLower(str)
 	}
-	// byte
-	matchBytes := utils.Str2Bytes(str)
-	findIndex := sm.pattern.FindSubmatch(matchBytes)
+	reg := sm.compile()
+	if reg == nil {
+		return false
+	}
+	findIndex := reg.FindStringSubmatch(str)
 	if len(findIndex) > 0 {
-		if uriTemplateVariables != nil {
-			// SPR-8455
-			if len(sm.variableNames) != sm.GroupCount() {
-				panic("The number of capturing groups in the pattern segment " +
-					sm.pattern.String() + " does not match the number of URI template variables it defines, " +
-					"which can occur if capturing groups are used in a URI template regex. " +
-					"Use non-capturing groups instead.")
-			}
-			for i := 1; i <= sm.GroupCount(); i++ {
-				name := sm.variableNames[i-1]
-				// 获取匹配位置
-				matched := findIndex[i]
-				value := utils.Bytes2Str(matched)
-				(*uriTemplateVariables)[*name] = value
-			}
+		// SPR-8455
+		if len(sm.variableNames) != sm.GroupCount() {
+			panic("The number of capturing groups in the pattern segment " +
+				sm.pattern.String() + " does not match the number of URI template variables it defines, " +
+				"which can occur if capturing groups are used in a URI template regex. " +
+				"Use non-capturing groups instead.")
+		}
+		for i := 1; i <= sm.GroupCount(); i++ {
+			*params = append(*params, Param{Key: *sm.variableNames[i-1], Value: findIndex[i]})
 		}
 		return true
-	} else {
-		return false
 	}
+	return false
+}
+
+// Matches 只判断字符串是否匹配，不提取变量，不产生堆分配
+func (sm *AntPathStringMatcher) Matches(str string) bool {
+	// 正则表达式中的"."不匹配换行符，含有换行符的输入交给正则表达式处理以保持相同的结果
+	if sm.tokens != nil && strings.IndexByte(str, '\n') == -1 {
+		return globMatch(sm.tokens, str, !sm.caseSensitive)
+	}
+	if !sm.caseSensitive {
+		str = strings.ToLower(str)
+	}
+	reg := sm.compile()
+	return reg != nil && reg.MatchString(str)
+}
+
+// globMatch 使用双指针算法匹配"*"与"?"，"*"失配时只回溯到最近的一个"*"
+func globMatch(tokens []segmentToken, str string, fold bool) bool {
+	p, s := 0, 0
+	starP, starS := -1, 0
+	for s < len(str) {
+		r, size := utf8.DecodeRuneInString(str[s:])
+		if fold {
+			r = unicode.ToLower(r)
+		}
+		if p < len(tokens) {
+			switch tokens[p].kind {
+			case starToken:
+				starP, starS = p, s
+				p++
+				continue
+			case anyToken:
+				p++
+				s += size
+				continue
+			case literalToken:
+				if tokens[p].char == r {
+					p++
+					s += size
+					continue
+				}
+			}
+		}
+		if starP == -1 {
+			return false
+		}
+		// 让最近的"*"多匹配一个字符后重试
+		_, size = utf8.DecodeRuneInString(str[starS:])
+		starS += size
+		p, s = starP+1, starS
+	}
+	for p < len(tokens) && tokens[p].kind == starToken {
+		p++
+	}
+	return p == len(tokens)
 }
 
 // GroupCount
@@ -641,8 +875,12 @@ func (sm *AntPathStringMatcher) GroupCount() int {
 
 // FindSubMatch 子查询
 func (sm *AntPathStringMatcher) FindSubMatch(source []byte, index int) *string {
-	indexCollection := sm.pattern.FindSubmatch(source)
-	result := utils.Bytes2Str(indexCollection[index])
+	result := utils.EmptyString
+	if reg := sm.compile(); reg != nil {
+		if indexCollection := reg.FindSubmatch(source); index < len(indexCollection) {
+			result = utils.Bytes2Str(indexCollection[index])
+		}
+	}
 	return &result
 }
 
@@ -0,0 +1,88 @@
+package antstyle
+
+import "testing"
+
+// zeroAllocCases 不含变量的模式，预热后Match不应产生堆分配
+var zeroAllocCases = []struct {
+	name    string
+	pattern string
+	path    string
+}{
+	{"Literal", "/api/users/list", "/api/users/list"},
+	{"Prefix", "/static/**", "/static/css/site.css"},
+	{"Extension", "/**/*.jsp", "/WEB-INF/views/home.jsp"},
+	{"Glob", "/api/*/users/?", "/api/v1/users/7"},
+	{"DoubleWildcard", "/api/**/users/*.json", "/api/v1/internal/users/all.json"},
+	{"NoMatch", "/api/**/users/*.json", "/api/v1/internal/groups/all.json"},
+}
+
+func TestMatchZeroAllocs(t *testing.T) {
+	ant := New()
+	for _, c := range zeroAllocCases {
+		ant.Match(c.pattern, c.path)
+		allocs := testing.AllocsPerRun(100, func() {
+			ant.Match(c.pattern, c.path)
+		})
+		if allocs != 0 {
+			t.Errorf("Match(%q, %q) allocates %v times per run", c.pattern, c.path, allocs)
+		}
+	}
+}
+
+func TestExtractParamsZeroAllocs(t *testing.T) {
+	ant := New()
+	params := AcquireParams()
+	defer ReleaseParams(params)
+	ant.ExtractParams("/hotels/{hotel}/bookings/{booking}", "/hotels/1/bookings/2", params)
+	allocs := testing.AllocsPerRun(100, func() {
+		ant.ExtractParams("/hotels/{hotel}/bookings/{booking}", "/hotels/1/bookings/2", params)
+	})
+	if allocs != 0 {
+		t.Errorf("ExtractParams with pooled Params allocates %v times per run", allocs)
+	}
+}
+
+func benchmarkMatch(b *testing.B, pattern, path string) {
+	ant := New()
+	ant.Match(pattern, path)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ant.Match(pattern, path)
+	}
+}
+
+func BenchmarkMatchLiteral(b *testing.B) {
+	benchmarkMatch(b, zeroAllocCases[0].pattern, zeroAllocCases[0].path)
+}
+
+func BenchmarkMatchPrefix(b *testing.B) {
+	benchmarkMatch(b, zeroAllocCases[1].pattern, zeroAllocCases[1].path)
+}
+
+func BenchmarkMatchExtension(b *testing.B) {
+	benchmarkMatch(b, zeroAllocCases[2].pattern, zeroAllocCases[2].path)
+}
+
+func BenchmarkMatchGlob(b *testing.B) {
+	benchmarkMatch(b, zeroAllocCases[3].pattern, zeroAllocCases[3].path)
+}
+
+func BenchmarkMatchDoubleWildcard(b *testing.B) {
+	benchmarkMatch(b, zeroAllocCases[4].pattern, zeroAllocCases[4].path)
+}
+
+func BenchmarkMatchVariables(b *testing.B) {
+	benchmarkMatch(b, "/hotels/{hotel}/bookings/{booking}", "/hotels/1/bookings/2")
+}
+
+func BenchmarkExtractParams(b *testing.B) {
+	ant := New()
+	params := AcquireParams()
+	defer ReleaseParams(params)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ant.ExtractParams("/hotels/{hotel}/bookings/{booking}", "/hotels/1/bookings/2", params)
+	}
+}
@@ -16,7 +16,7 @@ var (
 	Brackets        rune           = '\u007b'                                 // {
 	WildcardChars   []rune         = []rune{Asterisk, QuestionMark, Brackets} // 通配符字符首字母'*'，'？'，'{'
 	VariablePattern *regexp.Regexp                                            // pattern
-	matcher         PathMatcher
+	matcher         *AntPathMatcher
 )
 
 func init() {
@@ -38,10 +38,47 @@ func Match(pattern, path string) bool {
 	return matcher.Match(pattern, path)
 }
 
+func MatchSegments(pattern string, segments []string) bool {
+	return matcher.MatchSegments(pattern, segments)
+}
+
+func MatchBytes(pattern string, path []byte) bool {
+	return matcher.MatchBytes(pattern, path)
+}
+
 func MatchStart(pattern, path string) bool {
 	return matcher.MatchStart(pattern, path)
 }
 
+func ExtractParams(pattern, path string, params *Params) bool {
+	return matcher.ExtractParams(pattern, path, params)
+}
+
+func Normalize(pattern string) string {
+	return matcher.Normalize(pattern)
+}
+
+func Covers(a, b string) bool {
+	return matcher.Covers(a, b)
+}
+
+func Overlaps(a, b string) (bool, string) {
+	return matcher.Overlaps(a, b)
+}
+
+func DetectConflicts(patterns []string) []Conflict {
+	return matcher.DetectConflicts(patterns)
+}
+
+func Inspect(pattern string) PatternDescription {
+	return matcher.Inspect(pattern)
+}
+
+// SortPatterns 按照对给定路径的具体程度对模式进行稳定排序，最具体的模式排在最前
+func SortPatterns(path string, patterns []string) {
+	matcher.GetPatternComparator(path).Sort(patterns)
+}
+
 func SetPathSeparator(pathSeparator string) {
 	matcher.SetPathSeparator(pathSeparator)
 }
@@ -58,6 +95,34 @@ func SetCachePatterns(cachePatterns bool) {
 	matcher.SetCachePatterns(cachePatterns)
 }
 
+func SetLimits(limits Limits) {
+	matcher.SetLimits(limits)
+}
+
+func SetResultCacheSize(size int) {
+	matcher.SetResultCacheSize(size)
+}
+
+func GetResultCacheStats() ResultCacheStats {
+	return matcher.GetResultCacheStats()
+}
+
+func TryMatch(pattern, path string) (bool, error) {
+	return matcher.TryMatch(pattern, path)
+}
+
+func ValidatePattern(pattern string) error {
+	return matcher.ValidatePattern(pattern)
+}
+
+func TryCombine(pattern1, pattern2 string) (string, error) {
+	return matcher.TryCombine(pattern1, pattern2)
+}
+
+func Rewrite(fromPattern, toTemplate, path string) (string, bool, error) {
+	return matcher.Rewrite(fromPattern, toTemplate, path)
+}
+
 /*
 *
   *策略界面，用于基于路径的匹配。
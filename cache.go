@@ -8,6 +8,8 @@ type PathSeparatorPatternCache struct {
 	endsOnWildCard string
 	// "**"
 	endsOnDoubleWildCard string
+	// "//"
+	doubleSeparator string
 }
 
 // NewDefaultPathSeparatorPatternCache 构造函数
@@ -15,6 +17,7 @@ func NewDefaultPathSeparatorPatternCache(pathSeparator string) *PathSeparatorPat
 	patternCache := &PathSeparatorPatternCache{}
 	patternCache.endsOnWildCard = pathSeparator + "*"
 	patternCache.endsOnDoubleWildCard = pathSeparator + "**"
+	patternCache.doubleSeparator = pathSeparator + pathSeparator
 	return patternCache
 }
 
@@ -27,3 +30,8 @@ func (patternCache *PathSeparatorPatternCache) GetEndsOnWildCard() string {
 func (patternCache *PathSeparatorPatternCache) GetEndsOnDoubleWildCard() string {
 	return patternCache.endsOnDoubleWildCard
 }
+
+// GetDoubleSeparator 返回连续的两个路径分隔符 "//"
+func (patternCache *PathSeparatorPatternCache) GetDoubleSeparator() string {
+	return patternCache.doubleSeparator
+}
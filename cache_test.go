@@ -0,0 +1,85 @@
+package antstyle
+
+import "testing"
+
+// settingCases 每个设置项改变后，同一模式与路径的匹配结果都会改变
+var settingCases = []struct {
+	name      string
+	configure func(ant *AntPathMatcher)
+	pattern   string
+	path      string
+}{
+	{"CaseSensitive", func(ant *AntPathMatcher) { ant.SetCaseSensitive(false) }, "/API/*", "/api/users"},
+	{"CaseSensitiveVariable", func(ant *AntPathMatcher) { ant.SetCaseSensitive(false) }, "/{id:[a-z]+}", "/ABC"},
+	{"PathSeparator", func(ant *AntPathMatcher) { ant.SetPathSeparator(".") }, "a.*", "a.b.c"},
+	{"TrimTokens", func(ant *AntPathMatcher) { ant.SetTrimTokens(true) }, "/a/b", "/a/ b "},
+	{"Limits", func(ant *AntPathMatcher) { ant.SetLimits(Limits{MaxDoubleWildcards: 1}) }, "/a/**/b/**", "/a/x/b/y"},
+}
+
+// fresh 返回按configure配置且没有任何缓存内容的匹配器
+func fresh(configure func(ant *AntPathMatcher)) *AntPathMatcher {
+	ant := New()
+	configure(ant)
+	return ant
+}
+
+func TestSettersInvalidateCachedMatches(t *testing.T) {
+	for _, c := range settingCases {
+		t.Run(c.name, func(t *testing.T) {
+			ant := New()
+			ant.SetResultCacheSize(16)
+			before := ant.Match(c.pattern, c.path)
+			want := fresh(c.configure).Match(c.pattern, c.path)
+			if before == want {
+				t.Fatalf("Match(%q, %q) = %v with both settings, case does not exercise the setter", c.pattern, c.path, before)
+			}
+			c.configure(ant)
+			if got := ant.Match(c.pattern, c.path); got != want {
+				t.Errorf("Match(%q, %q) after setter = %v, want %v", c.pattern, c.path, got, want)
+			}
+		})
+	}
+}
+
+func TestSettersWhileCachePatternsDisabled(t *testing.T) {
+	for _, c := range settingCases {
+		t.Run(c.name, func(t *testing.T) {
+			ant := New()
+			ant.Match(c.pattern, c.path)
+			ant.SetCachePatterns(false)
+			c.configure(ant)
+			want := fresh(c.configure).Match(c.pattern, c.path)
+			if got := ant.Match(c.pattern, c.path); got != want {
+				t.Errorf("Match(%q, %q) without cache = %v, want %v", c.pattern, c.path, got, want)
+			}
+			ant.SetCachePatterns(true)
+			for i := 0; i < 2; i++ {
+				if got := ant.Match(c.pattern, c.path); got != want {
+					t.Errorf("Match(%q, %q) with cache re-enabled = %v, want %v", c.pattern, c.path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSharedCachesAreKeyedByConfiguration(t *testing.T) {
+	for _, c := range settingCases {
+		t.Run(c.name, func(t *testing.T) {
+			tokenized, segments := NewMapPatternCache(), NewMapPatternCache()
+			plain := NewWithCache(DefaultPathSeparator, tokenized, segments)
+			configured := NewWithCache(DefaultPathSeparator, tokenized, segments)
+			c.configure(configured)
+			wantPlain := New().Match(c.pattern, c.path)
+			wantConfigured := fresh(c.configure).Match(c.pattern, c.path)
+			// 两个匹配器交替使用同一对缓存，任何一方都不能读到另一方的预处理结果
+			for i := 0; i < 2; i++ {
+				if got := plain.Match(c.pattern, c.path); got != wantPlain {
+					t.Errorf("default Match(%q, %q) = %v, want %v", c.pattern, c.path, got, wantPlain)
+				}
+				if got := configured.Match(c.pattern, c.path); got != wantConfigured {
+					t.Errorf("configured Match(%q, %q) = %v, want %v", c.pattern, c.path, got, wantConfigured)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package antstyle
+
+import "testing"
+
+// combineCases Spring AntPathMatcherTests.combine中的用例
+var combineCases = []struct {
+	pattern1 string
+	pattern2 string
+	want     string
+}{
+	{"", "", ""},
+	{"/hotels", "", "/hotels"},
+	{"", "/hotels", "/hotels"},
+	{"/hotels/*", "booking", "/hotels/booking"},
+	{"/hotels/*", "/booking", "/hotels/booking"},
+	{"/hotels/**", "booking", "/hotels/**/booking"},
+	{"/hotels/**", "/booking", "/hotels/**/booking"},
+	{"/hotels", "/booking", "/hotels/booking"},
+	{"/hotels", "booking", "/hotels/booking"},
+	{"/hotels/", "booking", "/hotels/booking"},
+	{"/hotels/*", "{hotel}", "/hotels/{hotel}"},
+	{"/hotels/**", "{hotel}", "/hotels/**/{hotel}"},
+	{"/hotels", "{hotel}", "/hotels/{hotel}"},
+	{"/hotels", "{hotel}.*", "/hotels/{hotel}.*"},
+	{"/hotels/*/booking", "{booking}", "/hotels/*/booking/{booking}"},
+	{"/*.html", "/hotel.html", "/hotel.html"},
+	{"/*.html", "/hotel", "/hotel.html"},
+	{"/*.html", "/hotel.*", "/hotel.html"},
+	{"/**", "/*.html", "/*.html"},
+	{"/*", "/*.html", "/*.html"},
+	{"/*.*", "/*.html", "/*.html"},
+	{"/{foo}", "/bar", "/{foo}/bar"},
+	{"/user", "/user", "/user/user"},
+	{"/{foo:.*[^0-9].*}", "/edit/", "/{foo:.*[^0-9].*}/edit/"},
+	{"/1.0", "/foo/test", "/1.0/foo/test"},
+	{"/", "/hotel", "/hotel"},
+	{"/hotel/", "/booking", "/hotel/booking"},
+}
+
+func TestTryCombine(t *testing.T) {
+	ant := New()
+	for _, c := range combineCases {
+		got, err := ant.TryCombine(c.pattern1, c.pattern2)
+		if err != nil || got != c.want {
+			t.Errorf("TryCombine(%q, %q) = %q, %v, want %q", c.pattern1, c.pattern2, got, err, c.want)
+		}
+		if got := ant.Combine(c.pattern1, c.pattern2); got != c.want {
+			t.Errorf("Combine(%q, %q) = %q, want %q", c.pattern1, c.pattern2, got, c.want)
+		}
+	}
+}
+
+func TestTryCombineCustomSeparator(t *testing.T) {
+	cases := []struct {
+		pattern1 string
+		pattern2 string
+		want     string
+	}{
+		{"::hotels::*", "booking", "::hotels::booking"},
+		{"::hotels::*", "::booking", "::hotels::booking"},
+		{"::hotels::", "::booking", "::hotels::booking"},
+		{"::*.html", "::hotel", "::hotel.html"},
+		{"::a.b::*.html", "::x.y::hotel", "::x.y::hotel.html"},
+	}
+	ant := NewS("::")
+	for _, c := range cases {
+		if got, err := ant.TryCombine(c.pattern1, c.pattern2); err != nil || got != c.want {
+			t.Errorf("TryCombine(%q, %q) = %q, %v, want %q", c.pattern1, c.pattern2, got, err, c.want)
+		}
+	}
+}
+
+func TestTryCombineErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		pattern1 string
+		pattern2 string
+	}{
+		{"ConflictingExtensions", "/*.html", "/*.txt"},
+		{"DuplicateVariable", "/{id}", "/{id}"},
+		{"DuplicateRegexVariable", "/{id:\\d+}", "/x/{id}"},
+	}
+	ant := New()
+	for _, c := range cases {
+		if got, err := ant.TryCombine(c.pattern1, c.pattern2); err == nil {
+			t.Errorf("%s: TryCombine(%q, %q) = %q, want error", c.name, c.pattern1, c.pattern2, got)
+		}
+	}
+}
+
+func TestCombineConflictingExtensionsPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Combine(\"/*.html\", \"/*.txt\") did not panic")
+		}
+	}()
+	New().Combine("/*.html", "/*.txt")
+}
@@ -1,6 +1,9 @@
 package antstyle
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 // AntPatternComparator
 /**
@@ -14,7 +17,9 @@ import "strings"
  *如果比其他格式短
  */
 type AntPatternComparator struct {
-	path string
+	path       string
+	totalOrder bool                                // Compare是否按sortKey比较
+	tieBreaker func(pattern1, pattern2 string) int // 比较结果为0时使用的最终比较，默认为nil
 }
 
 func NewDefaultAntPatternComparator(path string) *AntPatternComparator {
@@ -23,7 +28,118 @@ func NewDefaultAntPatternComparator(path string) *AntPatternComparator {
 	return comparator
 }
 
+// NewTotalOrderAntPatternComparator 按sortKey比较并以字典序作为最终比较的比较器
+/**
+ *默认比较器的规则来自Spring，并不满足传递性，例如"/a/**"、"/{x}/{y}/{z}"与"/**\/b/{c}"。
+ *全序比较器为每个模式计算排序键并按字典序比较，传递性由构造保证，不同的模式之间总有确定的先后顺序，
+ *排序结果与输入的排列无关。
+ */
+func NewTotalOrderAntPatternComparator(path string) *AntPatternComparator {
+	comparator := NewDefaultAntPatternComparator(path)
+	comparator.totalOrder = true
+	comparator.SetTieBreaker(strings.Compare)
+	return comparator
+}
+
+// SetTieBreaker 设置Compare返回0时使用的最终比较，nil表示不使用
+func (comparator *AntPatternComparator) SetTieBreaker(tieBreaker func(pattern1, pattern2 string) int) {
+	comparator.tieBreaker = tieBreaker
+}
+
+// Func 返回可直接用于slices.SortFunc的比较函数
+func (comparator *AntPatternComparator) Func() func(pattern1, pattern2 string) int {
+	return comparator.Compare
+}
+
+// Sort 按从最具体到最通用的顺序对模式进行稳定排序
+/**
+ *无论是否为全序比较器都按sortKey排序，排序键与最终比较都相同的模式保持原有的相对顺序。
+ */
+func (comparator *AntPatternComparator) Sort(patterns []string) {
+	keys := make(map[string]sortKey, len(patterns))
+	for _, pattern := range patterns {
+		keys[pattern] = comparator.sortKey(pattern)
+	}
+	sort.SliceStable(patterns, func(i, j int) bool {
+		result := keys[patterns[i]].compare(keys[patterns[j]])
+		if result == 0 && comparator.tieBreaker != nil {
+			result = comparator.tieBreaker(patterns[i], patterns[j])
+		}
+		return result < 0
+	})
+}
+
 func (comparator *AntPatternComparator) Compare(pattern1, pattern2 string) int {
+	var result int
+	if comparator.totalOrder {
+		result = comparator.sortKey(pattern1).compare(comparator.sortKey(pattern2))
+	} else {
+		result = comparator.compare(pattern1, pattern2)
+	}
+	if result != 0 || comparator.tieBreaker == nil {
+		return result
+	}
+	return comparator.tieBreaker(pattern1, pattern2)
+}
+
+// sortKey 模式的排序键，按字段的顺序逐个比较，值较小的模式更具体
+/**
+ *规则与compare相同，只有一处不同：compare只将前缀模式排在不含"**"的模式之后，
+ *与其他含有"**"的模式仍按数量比较，因而不满足传递性；排序键将前缀模式排在所有非前缀模式之后。
+ */
+type sortKey struct {
+	leastSpecific   bool // 为空或"/**"
+	notPath         bool // 不等于要比较的路径
+	prefix          bool // 以"/**"结尾
+	totalCount      int
+	negativeLength  int // 长度取负，较长的模式更具体
+	singleWildcards int
+	uriVars         int
+}
+
+// sortKey 计算模式对比较器路径的排序键
+func (comparator *AntPatternComparator) sortKey(pattern string) sortKey {
+	info := NewDefaultPatternInfo(pattern)
+	return sortKey{
+		leastSpecific:   info.IsLeastSpecific(),
+		notPath:         !strings.EqualFold(comparator.path, pattern),
+		prefix:          info.IsPrefixPattern(),
+		totalCount:      info.GetTotalCount(),
+		negativeLength:  -info.GetLength(),
+		singleWildcards: info.GetSingleWildcards(),
+		uriVars:         info.GetUriVars(),
+	}
+}
+
+// compare 按字段的顺序比较两个排序键
+func (key sortKey) compare(other sortKey) int {
+	if key.leastSpecific && other.leastSpecific {
+		return 0
+	}
+	flags := [][2]bool{{key.leastSpecific, other.leastSpecific}, {key.notPath, other.notPath}, {key.prefix, other.prefix}}
+	for _, flag := range flags {
+		if flag[0] != flag[1] {
+			if flag[0] {
+				return 1
+			}
+			return -1
+		}
+	}
+	counts := [][2]int{
+		{key.totalCount, other.totalCount},
+		{key.negativeLength, other.negativeLength},
+		{key.singleWildcards, other.singleWildcards},
+		{key.uriVars, other.uriVars},
+	}
+	for _, count := range counts {
+		if count[0] != count[1] {
+			return count[0] - count[1]
+		}
+	}
+	return 0
+}
+
+func (comparator *AntPatternComparator) compare(pattern1, pattern2 string) int {
 	info1 := NewDefaultPatternInfo(pattern1)
 	info2 := NewDefaultPatternInfo(pattern2)
 
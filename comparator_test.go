@@ -0,0 +1,78 @@
+package antstyle
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// permutations 返回patterns的所有排列
+func permutations(patterns []string) [][]string {
+	if len(patterns) <= 1 {
+		return [][]string{append([]string(nil), patterns...)}
+	}
+	result := make([][]string, 0)
+	for i := range patterns {
+		rest := append(append([]string(nil), patterns[:i]...), patterns[i+1:]...)
+		for _, permutation := range permutations(rest) {
+			result = append(result, append([]string{patterns[i]}, permutation...))
+		}
+	}
+	return result
+}
+
+func TestTotalOrderIsPermutationInvariant(t *testing.T) {
+	patterns := []string{"/a/**", "/{x}/{y}/{z}", "/**/b/{c}", "/a/b/c", "/a/*/c", "/**", "/a/{y}/c"}
+	path := "/a/b/c"
+	var want []string
+	for _, permutation := range permutations(patterns) {
+		sorted := append([]string(nil), permutation...)
+		NewTotalOrderAntPatternComparator(path).Sort(sorted)
+		if want == nil {
+			want = sorted
+		} else if !reflect.DeepEqual(sorted, want) {
+			t.Fatalf("Sort(%v) = %v, want %v", permutation, sorted, want)
+		}
+		comparator := NewTotalOrderAntPatternComparator(path)
+		sort.Slice(permutation, func(i, j int) bool {
+			return comparator.Compare(permutation[i], permutation[j]) < 0
+		})
+		if !reflect.DeepEqual(permutation, want) {
+			t.Fatalf("sort.Slice with Compare = %v, want %v", permutation, want)
+		}
+	}
+	if want[0] != "/a/b/c" || want[len(want)-1] != "/**" {
+		t.Errorf("Sort = %v, want /a/b/c first and /** last", want)
+	}
+}
+
+func TestTotalOrderIsTransitive(t *testing.T) {
+	patterns := []string{"/a/**", "/{x}/{y}/{z}", "/**/b/{c}", "/a/b/c", "/a/*/c", "/**", "", "/a/{y}/c", "/*.html", "/a/**/c"}
+	comparator := NewTotalOrderAntPatternComparator("/a/b/c")
+	for _, a := range patterns {
+		for _, b := range patterns {
+			if a != b && comparator.Compare(a, b) == 0 {
+				t.Errorf("Compare(%q, %q) = 0", a, b)
+			}
+			if sign(comparator.Compare(a, b)) != -sign(comparator.Compare(b, a)) {
+				t.Errorf("Compare(%q, %q) is not antisymmetric", a, b)
+			}
+			for _, c := range patterns {
+				if comparator.Compare(a, b) < 0 && comparator.Compare(b, c) < 0 && comparator.Compare(a, c) >= 0 {
+					t.Errorf("%q < %q < %q but Compare(%q, %q) >= 0", a, b, c, a, c)
+				}
+			}
+		}
+	}
+}
+
+// sign 返回整数的符号
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,289 @@
+package antstyle
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aluka-7/utils"
+)
+
+const (
+	pathSegmentBufferSize = 32 // 匹配时在栈上为路径段预留的空间，段数不超过它的路径不会产生堆分配
+	nfaBufferWords        = 2  // 匹配"**"时在栈上为NFA状态集合预留的空间，可容纳127个模式段
+)
+
+// patternShape 模式的形状，决定Match能否绕过doMatch的逐段匹配
+type patternShape int
+
+const (
+	generalShape   patternShape = iota // 其他模式，交给doMatch逐段匹配
+	exactShape                         // 全部为字面量段，例如"/api/users"
+	prefixShape                        // 字面量段后接"**"，例如"/static/**"
+	extensionShape                     // "**"后接"*.ext"，例如"/**/*.jsp"
+)
+
+// compiledPattern 预处理后的模式，由tokenizedPatternCache缓存
+/**
+ *模式在第一次使用时被切分为段，并为每一段确定匹配方式：
+ *字面量段直接比较字符串，"**"由doMatch处理，其余的段使用AntPathStringMatcher。
+ *同时按形状对模式分类，常见的三种形状可以在整条路径上直接用字符串比较完成匹配。
+ */
+type compiledPattern struct {
+	pattern  string
+	leading  bool // 是否以路径分隔符开头
+	trailing bool // 是否以路径分隔符结尾
+	segments []compiledSegment
+
+	shape         patternShape
+	literalPrefix string // 开头连续的字面量段，连同开头的分隔符，例如"/api/v1"
+	extension     string // extensionShape中"*"之后的字面量后缀，例如".jsp"
+
+	err error // 模式超出Limits或正则约束无法编译时的错误，此时segments为空
+}
+
+// compiledSegment 预处理后的模式段
+type compiledSegment struct {
+	text    string                // 段的原始文本
+	lower   string                // 不区分大小写时用于比较的小写文本
+	kind    SegmentKind           // 段的种类
+	matcher *AntPathStringMatcher // 通配段与变量段使用的匹配器，字面量段与"**"为nil
+}
+
+// compilePattern 返回给定模式的compiledPattern，遵循setCachePatterns的设置进行缓存
+func (ant *AntPathMatcher) compilePattern(pattern string) *compiledPattern {
+	cachePatterns := ant.cachePatterns
+	key := PatternKey{
+		Pattern:       pattern,
+		PathSeparator: ant.pathSeparator,
+		CaseSensitive: ant.caseSensitive,
+		TrimTokens:    ant.trimTokens,
+		Limits:        ant.limits,
+	}
+	if cachePatterns {
+		if value, ok := ant.tokenizedPatternCache.Get(key); ok {
+			return value.(*compiledPattern)
+		}
+	}
+	compiled := &compiledPattern{
+		pattern:  pattern,
+		leading:  strings.HasPrefix(pattern, ant.pathSeparator),
+		trailing: strings.HasSuffix(pattern, ant.pathSeparator),
+		segments: make([]compiledSegment, 0),
+	}
+	compiled.err = checkLimit("MaxPatternLength", len(pattern), ant.limits.MaxPatternLength, pattern)
+	if compiled.err == nil {
+		// 在编译任何正则表达式之前检查限制
+		segments := make([]*patternSegment, 0)
+		for _, token := range ant.tokenizePath(pattern) {
+			segments = append(segments, parseSegment(*token))
+		}
+		compiled.err = ant.limits.checkPattern(pattern, segments)
+		if compiled.err == nil {
+			compiled.err = ant.compileSegments(compiled, segments)
+		}
+	}
+	if cachePatterns && ant.cachePatterns {
+		if ant.defaultCaches && ant.tokenizedPatternCache.Len() >= CacheTurnoffThreshold {
+			ant.deactivatePatternCache()
+			return compiled
+		}
+		ant.tokenizedPatternCache.Put(key, compiled)
+	}
+	return compiled
+}
+
+// compileSegments 为每一段确定匹配方式，带有正则约束的段立即编译，无法编译时返回错误
+func (ant *AntPathMatcher) compileSegments(compiled *compiledPattern, segments []*patternSegment) error {
+	compiledSegments := make([]compiledSegment, 0, len(segments))
+	for _, segment := range segments {
+		compiledSegment := compiledSegment{text: segment.text, lower: strings.ToLower(segment.text), kind: segment.kind}
+		if segment.kind == GlobSegment || segment.kind == VariableSegment {
+			compiledSegment.matcher = ant.getStringMatcher(segment.text)
+			if segment.hasRegex() && compiledSegment.matcher.compile() == nil {
+				return fmt.Errorf("antstyle: invalid regular expression in segment %q of pattern %q: %w", segment.text, compiled.pattern, compiledSegment.matcher.err)
+			}
+		}
+		compiledSegments = append(compiledSegments, compiledSegment)
+	}
+	compiled.segments = compiledSegments
+	ant.classifyPattern(compiled)
+	return nil
+}
+
+// classifyPattern 确定模式的形状与字面量前缀
+/**
+ *只有在不去除空格、路径分隔符不含字母（大小写转换不会影响分隔符）且模式中没有连续分隔符时才进行分类，
+ *否则整条字符串的比较与逐段比较的结果可能不同。
+ */
+func (ant *AntPathMatcher) classifyPattern(compiled *compiledPattern) {
+	separator := ant.pathSeparator
+	if ant.trimTokens || strings.IndexFunc(separator, unicode.IsLetter) != -1 ||
+		strings.Contains(compiled.pattern, ant.pathSeparatorPatternCache.GetDoubleSeparator()) {
+		return
+	}
+	literals := 0
+	for literals < len(compiled.segments) && compiled.segments[literals].kind == LiteralSegment {
+		literals++
+	}
+	if literals > 0 {
+		texts := make([]string, literals)
+		for i := range texts {
+			texts[i] = compiled.segments[i].text
+		}
+		compiled.literalPrefix = strings.Join(texts, separator)
+		if compiled.leading {
+			compiled.literalPrefix = separator + compiled.literalPrefix
+		}
+	}
+
+	segments := compiled.segments
+	switch {
+	case literals == len(segments):
+		compiled.shape = exactShape
+	case literals > 0 && literals == len(segments)-1 && segments[literals].isDoubleWildcard():
+		compiled.shape = prefixShape
+	case len(segments) == 2 && segments[0].isDoubleWildcard() && segments[1].kind == GlobSegment:
+		extension := segments[1].text[1:]
+		if segments[1].text[0] == '*' && extension != "" && isLiteralSegment(extension) &&
+			strings.IndexAny(extension, separator) == -1 && !compiled.trailing {
+			compiled.shape = extensionShape
+			compiled.extension = extension
+		}
+	}
+}
+
+// matchShape 对分类过的模式直接在整条路径上进行比较
+/**
+ *只处理"干净"的路径，即不含连续的分隔符，此时路径的切分结果与字符串本身一一对应。
+ *返回的decided为false时表示无法判断，需要交给doMatch逐段匹配。
+ */
+func (ant *AntPathMatcher) matchShape(compiled *compiledPattern, path string) (matched, decided bool) {
+	if compiled.literalPrefix == "" && compiled.shape == generalShape {
+		return false, false
+	}
+	if strings.Contains(path, ant.pathSeparatorPatternCache.GetDoubleSeparator()) {
+		return false, false
+	}
+	separator := ant.pathSeparator
+	switch compiled.shape {
+	case exactShape:
+		rest, ok := ant.trimPrefix(path, compiled.pattern)
+		return ok && rest == "", true
+	case extensionShape:
+		// "*"与正则表达式中的"."一样不匹配换行符
+		if strings.HasSuffix(path, separator) || strings.IndexByte(path, '\n') != -1 {
+			return false, false
+		}
+		return strings.HasPrefix(path, separator) == compiled.leading && ant.hasSuffix(path, compiled.extension), true
+	}
+	rest, ok := ant.trimPrefix(path, compiled.literalPrefix)
+	if !ok {
+		// 字面量前缀不匹配，任何形状的模式都不可能匹配
+		return false, true
+	}
+	if compiled.shape == prefixShape {
+		return rest == "" || strings.HasPrefix(rest, separator), true
+	}
+	return false, false
+}
+
+// trimPrefix 按大小写设置判断str是否以prefix开头，返回剩余的部分
+func (ant *AntPathMatcher) trimPrefix(str, prefix string) (string, bool) {
+	if ant.caseSensitive {
+		if strings.HasPrefix(str, prefix) {
+			return str[len(prefix):], true
+		}
+		return str, false
+	}
+	for len(prefix) > 0 {
+		if len(str) == 0 {
+			return str, false
+		}
+		r1, size1 := utf8.DecodeRuneInString(str)
+		r2, size2 := utf8.DecodeRuneInString(prefix)
+		if unicode.ToLower(r1) != unicode.ToLower(r2) {
+			return str, false
+		}
+		str, prefix = str[size1:], prefix[size2:]
+	}
+	return str, true
+}
+
+// hasSuffix 按大小写设置判断str是否以suffix结尾
+func (ant *AntPathMatcher) hasSuffix(str, suffix string) bool {
+	if ant.caseSensitive {
+		return strings.HasSuffix(str, suffix)
+	}
+	for len(suffix) > 0 {
+		if len(str) == 0 {
+			return false
+		}
+		r1, size1 := utf8.DecodeLastRuneInString(str)
+		r2, size2 := utf8.DecodeLastRuneInString(suffix)
+		if unicode.ToLower(r1) != unicode.ToLower(r2) {
+			return false
+		}
+		str, suffix = str[:len(str)-size1], suffix[:len(suffix)-size2]
+	}
+	return true
+}
+
+// isDoubleWildcard 段是否为"**"
+func (segment *compiledSegment) isDoubleWildcard() bool {
+	return segment.kind == DoubleWildcardSegment
+}
+
+// match 判断路径段是否与模式段匹配，需要时将变量追加到params
+func (segment *compiledSegment) match(str string, caseSensitive bool, params *Params) bool {
+	if segment.kind == LiteralSegment {
+		if caseSensitive {
+			return str == segment.text
+		}
+		return equalLower(str, segment.lower)
+	}
+	if params == nil {
+		return segment.matcher.Matches(str)
+	}
+	return segment.matcher.matchParams(str, params)
+}
+
+// equalLower 判断str转为小写后是否等于lower，不产生新的字符串
+func equalLower(str, lower string) bool {
+	for len(str) > 0 && len(lower) > 0 {
+		r1, size1 := utf8.DecodeRuneInString(str)
+		r2, size2 := utf8.DecodeRuneInString(lower)
+		if unicode.ToLower(r1) != r2 {
+			return false
+		}
+		str, lower = str[size1:], lower[size2:]
+	}
+	return len(str) == 0 && len(lower) == 0
+}
+
+// splitPath 按路径分隔符原地切分路径，将非空的段追加到buf中
+/**
+ *与utils.TokenizeToStringArray的结果相同，但返回的是path的子串，
+ *当buf的容量足够时不产生任何堆分配。
+ */
+func (ant *AntPathMatcher) splitPath(path string, buf []string) []string {
+	separator := ant.pathSeparator
+	for len(path) > 0 {
+		token := path
+		idx := strings.Index(path, separator)
+		if idx == -1 {
+			path = utils.EmptyString
+		} else {
+			token = path[:idx]
+			path = path[idx+len(separator):]
+		}
+		if ant.trimTokens {
+			token = strings.Trim(token, utils.EmptySpace)
+		}
+		if token != utils.EmptyString {
+			buf = append(buf, token)
+		}
+	}
+	return buf
+}
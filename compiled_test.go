@@ -0,0 +1,94 @@
+package antstyle
+
+import (
+	"strings"
+	"testing"
+)
+
+// shapePatterns 覆盖每种形状以及只有字面量前缀的一般模式，"/"会被替换为匹配器的分隔符
+var shapePatterns = []string{
+	"/api/users", "api/users", "/api/users/", "/API", "/",
+	"/static/**", "static/**", "/static/css/**", "/**",
+	"/**/*.jsp", "**/*.jsp", "/**/*.JSP", "/**/*.j/sp",
+	"/api/*/users", "/api/**/b", "/static/{file}",
+}
+
+// shapePaths 枚举由给定单词组成的最多3段的路径，包含开头、结尾与连续的分隔符
+func shapePaths() []string {
+	words := []string{"", "api", "API", "users", "static", "css", "x.jsp", "a.JSP", ".jsp", "x\n.jsp", "b"}
+	paths := []string{""}
+	level := []string{""}
+	for depth := 0; depth < 3; depth++ {
+		next := make([]string, 0)
+		for _, prefix := range level {
+			for _, word := range words {
+				next = append(next, prefix+"/"+word)
+			}
+		}
+		paths = append(paths, next...)
+		level = next
+	}
+	for _, p := range paths {
+		if p != "" {
+			paths = append(paths, p[1:], p+"/")
+		}
+	}
+	return paths
+}
+
+func TestMatchShapeAgreesWithMatchSegments(t *testing.T) {
+	paths := shapePaths()
+	for _, separator := range []string{"/", "."} {
+		for _, caseSensitive := range []bool{true, false} {
+			ant := NewS(separator)
+			ant.SetCaseSensitive(caseSensitive)
+			decided := 0
+			for _, raw := range shapePatterns {
+				pattern := strings.ReplaceAll(raw, "/", separator)
+				compiled := ant.compilePattern(pattern)
+				for _, rawPath := range paths {
+					path := strings.ReplaceAll(rawPath, "/", separator)
+					if strings.HasPrefix(path, separator) != compiled.leading {
+						// cachedMatch在matchShape之前已经排除了这种情况
+						continue
+					}
+					matched, ok := ant.matchShape(compiled, path)
+					if !ok {
+						continue
+					}
+					decided++
+					want := ant.matchSegments(compiled, ant.splitPath(path, nil), strings.HasSuffix(path, separator), true, nil)
+					if matched != want {
+						t.Errorf("separator %q, caseSensitive %v: matchShape(%q, %q) = %v, matchSegments = %v",
+							separator, caseSensitive, pattern, path, matched, want)
+					}
+				}
+			}
+			if decided == 0 {
+				t.Errorf("separator %q, caseSensitive %v: matchShape never decided", separator, caseSensitive)
+			}
+		}
+	}
+}
+
+func TestShapeClassification(t *testing.T) {
+	cases := []struct {
+		pattern string
+		shape   patternShape
+	}{
+		{"/api/users", exactShape},
+		{"/static/**", prefixShape},
+		{"/**/*.jsp", extensionShape},
+		{"/**/*.jsp/", generalShape},
+		{"/**/*.j?p", generalShape},
+		{"/**/*", generalShape},
+		{"/api/*/users", generalShape},
+		{"/**/b", generalShape},
+	}
+	ant := New()
+	for _, c := range cases {
+		if got := ant.compilePattern(c.pattern).shape; got != c.shape {
+			t.Errorf("shape of %q = %v, want %v", c.pattern, got, c.shape)
+		}
+	}
+}
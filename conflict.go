@@ -0,0 +1,40 @@
+package antstyle
+
+// Conflict 两个能匹配同一条路径的模式
+type Conflict struct {
+	Pattern1 string // 在输入中靠前的模式
+	Pattern2 string // 在输入中靠后的模式
+	Path     string // 两个模式都能匹配的示例路径
+	Order    int    // AntPatternComparator在Path上比较Pattern1与Pattern2的结果
+}
+
+// IsAmbiguous 比较器在示例路径上无法区分两个模式（返回0）时，哪个模式胜出取决于它们在切片中的顺序
+func (conflict Conflict) IsAmbiguous() bool {
+	return conflict.Order == 0
+}
+
+// DetectConflicts 找出一组模式中所有能匹配同一条路径的模式对
+/**
+ *对每一对模式调用Overlaps，重叠时用GetPatternComparator在示例路径上比较两者，
+ *记录比较器给出的是严格的先后顺序还是平局。
+ *@param patterns 要检查的模式，例如全部路由
+ *@return []Conflict 按输入顺序排列的冲突，没有冲突时为空
+ */
+func (ant *AntPathMatcher) DetectConflicts(patterns []string) []Conflict {
+	conflicts := make([]Conflict, 0)
+	for i := 0; i < len(patterns); i++ {
+		for j := i + 1; j < len(patterns); j++ {
+			overlapping, path := ant.Overlaps(patterns[i], patterns[j])
+			if !overlapping {
+				continue
+			}
+			conflicts = append(conflicts, Conflict{
+				Pattern1: patterns[i],
+				Pattern2: patterns[j],
+				Path:     path,
+				Order:    ant.GetPatternComparator(path).Compare(patterns[i], patterns[j]),
+			})
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,328 @@
+// Package gateway 基于httputil.ReverseProxy与Ant模式的反向代理网关
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/aluka-7/antstyle"
+)
+
+const (
+	pathSeparator  = "/"
+	restPrefix     = "{*" // "{*name}"捕获模式末尾剩余的所有路径
+	doubleWildcard = "**"
+)
+
+// Route 网关的一条路由
+/**
+ *Pattern为Ant模式，最后一段可以是"{*name}"，它与"**"一样匹配剩余的所有路径，并将这部分路径（不含开头的"/"）保存到变量name中，
+ *name必须是由字母、数字与下划线组成且不以数字开头的标识符，"{*name}"只能作为完整的最后一段出现。
+ *Methods与Host为空时不限制请求方法与主机，Host按antstyle.HostMatcher的规则匹配，不含端口时忽略请求中的端口。
+ *Rewrite为转发到上游的路径模板，可以使用Host与Pattern中的变量，例如"/legacy/{*rest}"与"/v2/{rest}"；
+ *StripPrefix为true时转发ExtractPathWithinPattern去除匹配的前缀后的路径，两者都未设置时转发原始路径。
+ *转发的路径追加在Upstream的路径之后。
+ */
+type Route struct {
+	ID          string
+	Pattern     string
+	Methods     []string
+	Host        string
+	Upstream    string
+	Rewrite     string
+	StripPrefix bool
+}
+
+// route 预处理后的路由
+type route struct {
+	Route
+	pattern      string // "{*name}"替换为"**"后的模式
+	restName     string // "{*name}"中的变量名
+	restTemplate string // 引用最后的"**"捕获的内容的重写模板，例如"$2"
+	upstream     *url.URL
+	proxy        *httputil.ReverseProxy
+}
+
+// Gateway 按路由将请求转发到上游的http.Handler（线程安全）
+/**
+ *多条路由匹配同一个请求时，按以字典序作为最终比较的AntPatternComparator选择最具体的模式，模式相同时选择先添加的路由。
+ *没有匹配的路由时返回404，转发的路径超出Upstream的路径时返回400，上游不可用时返回502。
+ */
+type Gateway struct {
+	mutex   sync.RWMutex
+	matcher *antstyle.AntPathMatcher
+	hosts   *antstyle.HostMatcher
+	routes  []*route
+}
+
+// New 构造函数，路由无效时返回错误
+func New(routes ...Route) (*Gateway, error) {
+	gateway := &Gateway{matcher: antstyle.New(), hosts: antstyle.NewHostMatcher()}
+	for _, r := range routes {
+		if err := gateway.Add(r); err != nil {
+			return nil, err
+		}
+	}
+	return gateway, nil
+}
+
+// Add 添加路由
+/**
+ *检查上游URL、模式中"{*name}"的位置，以及Rewrite中的变量是否都在Host或Pattern中声明。
+ */
+func (gateway *Gateway) Add(r Route) error {
+	upstream, err := url.Parse(r.Upstream)
+	if err != nil {
+		return fmt.Errorf("gateway: route %q has invalid upstream %q: %v", r.ID, r.Upstream, err)
+	}
+	if upstream.Scheme == "" || upstream.Host == "" {
+		return fmt.Errorf("gateway: route %q upstream %q must be an absolute URL", r.ID, r.Upstream)
+	}
+	if r.Rewrite != "" && r.StripPrefix {
+		return fmt.Errorf("gateway: route %q cannot use both Rewrite and StripPrefix", r.ID)
+	}
+	compiled := &route{Route: r, pattern: r.Pattern, upstream: upstream}
+	if strings.Contains(r.Pattern, restPrefix) {
+		idx := strings.LastIndex(r.Pattern, pathSeparator) + len(pathSeparator)
+		last := r.Pattern[idx:]
+		if idx == 0 || strings.Contains(r.Pattern[:idx], restPrefix) || !strings.HasPrefix(last, restPrefix) ||
+			!strings.HasSuffix(last, "}") || !isIdentifier(last[len(restPrefix):len(last)-1]) {
+			return fmt.Errorf("gateway: route %q pattern %q must end with \"/{*name}\" and use it nowhere else", r.ID, r.Pattern)
+		}
+		compiled.restName = last[len(restPrefix) : len(last)-1]
+		compiled.pattern = r.Pattern[:idx] + doubleWildcard
+	}
+	if err := gateway.matcher.ValidatePattern(compiled.pattern); err != nil {
+		return fmt.Errorf("gateway: route %q: %v", r.ID, err)
+	}
+	description := gateway.matcher.Inspect(compiled.pattern)
+	if compiled.restName != "" {
+		// 最后的"**"是模式中的最后一个通配符
+		compiled.restTemplate = "$" + strconv.Itoa(description.SingleWildcards+description.DoubleWildcards)
+	}
+	declared := map[string]bool{compiled.restName: compiled.restName != ""}
+	for _, variable := range description.Variables {
+		declared[variable.Name] = true
+	}
+	if r.Host != "" {
+		for _, variable := range antstyle.NewS(antstyle.HostSeparator).Inspect(r.Host).Variables {
+			declared[variable.Name] = true
+		}
+	}
+	for _, name := range templateVariables(r.Rewrite) {
+		if !declared[name] {
+			return fmt.Errorf("gateway: route %q rewrite %q uses undeclared variable %q", r.ID, r.Rewrite, name)
+		}
+	}
+	compiled.proxy = &httputil.ReverseProxy{Director: func(request *http.Request) {
+		request.URL.Scheme = upstream.Scheme
+		request.URL.Host = upstream.Host
+		if upstream.RawQuery == "" || request.URL.RawQuery == "" {
+			request.URL.RawQuery = upstream.RawQuery + request.URL.RawQuery
+		} else {
+			request.URL.RawQuery = upstream.RawQuery + "&" + request.URL.RawQuery
+		}
+		if _, ok := request.Header["User-Agent"]; !ok {
+			// 与httputil.NewSingleHostReverseProxy相同，不使用默认的User-Agent
+			request.Header.Set("User-Agent", "")
+		}
+	}}
+
+	gateway.mutex.Lock()
+	defer gateway.mutex.Unlock()
+	gateway.routes = append(gateway.routes, compiled)
+	return nil
+}
+
+// Routes 返回按添加顺序排列的所有路由
+func (gateway *Gateway) Routes() []Route {
+	gateway.mutex.RLock()
+	defer gateway.mutex.RUnlock()
+	routes := make([]Route, 0, len(gateway.routes))
+	for _, r := range gateway.routes {
+		routes = append(routes, r.Route)
+	}
+	return routes
+}
+
+// ServeHTTP 选择路由，计算上游路径后转发请求
+/**
+ *请求路径中的"."与".."在选择路由之前按cleanPath解析，转发的路径解析后必须仍在Upstream的路径之内，否则返回400。
+ */
+func (gateway *Gateway) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	requestPath := cleanPath(request.URL.Path)
+	r, variables, ok := gateway.lookup(request, requestPath)
+	if !ok {
+		http.NotFound(writer, request)
+		return
+	}
+	path := requestPath
+	switch {
+	case r.Rewrite != "":
+		path = expandTemplate(r.Rewrite, variables)
+	case r.StripPrefix:
+		path = pathSeparator + gateway.matcher.ExtractPathWithinPattern(r.pattern, path)
+	}
+	upstreamPath := cleanPath(joinPath(r.upstream.Path, path))
+	if !withinPath(upstreamPath, r.upstream.Path) {
+		http.Error(writer, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	request = request.Clone(request.Context())
+	request.URL.Path = upstreamPath
+	request.URL.RawPath = ""
+	r.proxy.ServeHTTP(writer, request)
+}
+
+// lookup 返回与请求匹配的最具体的路由及其变量，path为已解析"."与".."的请求路径
+func (gateway *Gateway) lookup(request *http.Request, path string) (*route, map[string]string, bool) {
+	gateway.mutex.RLock()
+	candidates := make([]*route, 0)
+	for _, r := range gateway.routes {
+		if r.matchesMethod(request.Method) && gateway.matchesHost(r, request.Host) && gateway.matcher.Match(r.pattern, path) {
+			candidates = append(candidates, r)
+		}
+	}
+	gateway.mutex.RUnlock()
+	if len(candidates) == 0 {
+		return nil, nil, false
+	}
+	comparator := antstyle.NewTotalOrderAntPatternComparator(path)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return comparator.Compare(candidates[i].pattern, candidates[j].pattern) < 0
+	})
+	best := candidates[0]
+
+	variables := make(map[string]string)
+	params := make(antstyle.Params, 0)
+	if best.Host != "" {
+		gateway.hosts.ExtractParams(best.Host, gateway.requestHost(best, request.Host), &params)
+	}
+	pathParams := make(antstyle.Params, 0)
+	gateway.matcher.ExtractParams(best.pattern, path, &pathParams)
+	for _, param := range append(params, pathParams...) {
+		variables[param.Key] = param.Value
+	}
+	if best.restName != "" {
+		variables[best.restName], _, _ = gateway.matcher.Rewrite(best.pattern, best.restTemplate, path)
+	}
+	return best, variables, true
+}
+
+// matchesMethod 请求方法是否满足路由的条件
+func (r *route) matchesMethod(method string) bool {
+	if len(r.Methods) == 0 {
+		return true
+	}
+	for _, m := range r.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesHost 请求的主机是否满足路由的条件
+func (gateway *Gateway) matchesHost(r *route, host string) bool {
+	return r.Host == "" || gateway.hosts.Match(r.Host, gateway.requestHost(r, host))
+}
+
+// requestHost 路由的主机模式不含端口时去除请求主机中的端口
+func (gateway *Gateway) requestHost(r *route, host string) string {
+	if strings.Contains(r.Host, ":") {
+		return host
+	}
+	return (&url.URL{Host: host}).Hostname()
+}
+
+// isIdentifier name是否为由字母、数字与下划线组成且不以数字开头的标识符
+func isIdentifier(name string) bool {
+	for i, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// templateVariables 返回模板中"{name}"形式的变量名
+func templateVariables(template string) []string {
+	names := make([]string, 0)
+	for {
+		start := strings.Index(template, "{")
+		if start == -1 {
+			return names
+		}
+		end := strings.Index(template[start:], "}")
+		if end == -1 {
+			return names
+		}
+		names = append(names, template[start+1:start+end])
+		template = template[start+end+1:]
+	}
+}
+
+// expandTemplate 将模板中的"{name}"替换为变量的值
+func expandTemplate(template string, variables map[string]string) string {
+	var builder strings.Builder
+	for {
+		start := strings.Index(template, "{")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(template[start:], "}")
+		if end == -1 {
+			break
+		}
+		builder.WriteString(template[:start])
+		builder.WriteString(variables[template[start+1:start+end]])
+		template = template[start+end+1:]
+	}
+	builder.WriteString(template)
+	return builder.String()
+}
+
+// joinPath 拼接上游的路径与转发的路径，两者之间只保留一个"/"
+func joinPath(base, path string) string {
+	switch {
+	case base == "":
+		if !strings.HasPrefix(path, pathSeparator) {
+			return pathSeparator + path
+		}
+		return path
+	case strings.HasSuffix(base, pathSeparator) && strings.HasPrefix(path, pathSeparator):
+		return base + path[1:]
+	case !strings.HasSuffix(base, pathSeparator) && !strings.HasPrefix(path, pathSeparator):
+		return base + pathSeparator + path
+	}
+	return base + path
+}
+
+// cleanPath 与http.ServeMux相同，解析路径中的"."与".."并合并连续的"/"，保留结尾的"/"
+func cleanPath(p string) string {
+	if p == "" {
+		return pathSeparator
+	}
+	if !strings.HasPrefix(p, pathSeparator) {
+		p = pathSeparator + p
+	}
+	cleaned := path.Clean(p)
+	if strings.HasSuffix(p, pathSeparator) && cleaned != pathSeparator {
+		cleaned += pathSeparator
+	}
+	return cleaned
+}
+
+// withinPath 已解析的路径p是否等于base或位于base之下
+func withinPath(p, base string) bool {
+	base = strings.TrimSuffix(cleanPath(base), pathSeparator)
+	return p == base || strings.HasPrefix(p, base+pathSeparator)
+}
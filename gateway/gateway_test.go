@@ -0,0 +1,114 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newEcho 返回把名称与收到的路径、查询参数写回响应的上游
+func newEcho(name string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		io.WriteString(writer, name+" "+request.URL.Path+"?"+request.URL.RawQuery)
+	}))
+}
+
+func TestGatewayRouting(t *testing.T) {
+	a, b := newEcho("a"), newEcho("b")
+	defer a.Close()
+	defer b.Close()
+	gateway, err := New(
+		Route{ID: "legacy", Pattern: "/legacy/{*rest}", Upstream: a.URL, Rewrite: "/v2/{rest}"},
+		Route{ID: "versioned", Pattern: "/api/*/{*rest}", Upstream: a.URL, Rewrite: "/r/{rest}"},
+		Route{ID: "users", Pattern: "/users/{id}", Methods: []string{"GET"}, Upstream: b.URL + "/base?k=1", Rewrite: "/u/{id}"},
+		Route{ID: "users-any", Pattern: "/users/**", Upstream: a.URL},
+		Route{ID: "strip", Pattern: "/svc/**", Upstream: b.URL + "/inner", StripPrefix: true},
+		Route{ID: "tenant", Pattern: "/t/**", Host: "{tenant}.example.com", Upstream: a.URL, Rewrite: "/tenants/{tenant}"},
+		Route{ID: "fallback", Pattern: "/**", Upstream: a.URL},
+		Route{ID: "shadowed", Pattern: "/**", Upstream: b.URL},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name   string
+		method string
+		target string
+		host   string
+		want   string
+	}{
+		{"Rewrite", "GET", "/legacy/a/b", "", "a /v2/a/b?"},
+		{"RewriteRestOnly", "GET", "/api/v1/a/b", "", "a /r/a/b?"},
+		{"RewriteEmptyRest", "GET", "/legacy", "", "a /v2/?"},
+		{"RewriteUpstreamQuery", "GET", "/users/7?x=2", "", "b /base/u/7?k=1&x=2"},
+		{"MethodMismatch", "POST", "/users/7", "", "a /users/7?"},
+		{"StripPrefix", "GET", "/svc/x/y", "", "b /inner/x/y?"},
+		{"HostWithPort", "GET", "/t/z", "acme.example.com:8080", "a /tenants/acme?"},
+		{"HostMismatch", "GET", "/t/z", "example.org", "a /t/z?"},
+		{"SamePatternFirstAdded", "GET", "/other", "", "a /other?"},
+		{"StripPrefixDotDot", "GET", "/svc/../admin", "", "a /admin?"},
+		{"StripPrefixNestedDotDot", "GET", "/svc/a/../../admin", "", "a /admin?"},
+		{"StripPrefixEncodedDotDot", "GET", "/svc/%2e%2e/admin", "", "a /admin?"},
+		{"StripPrefixInnerDots", "GET", "/svc/x/./y/../z", "", "b /inner/x/z?"},
+		{"RewriteDotDot", "GET", "/legacy/../../etc", "", "a /etc?"},
+		{"RewriteInnerDotDot", "GET", "/legacy/a/../b", "", "a /v2/b?"},
+	}
+	for _, c := range cases {
+		request := httptest.NewRequest(c.method, c.target, nil)
+		if c.host != "" {
+			request.Host = c.host
+		}
+		recorder := httptest.NewRecorder()
+		gateway.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusOK || recorder.Body.String() != c.want {
+			t.Errorf("%s: %s %s = %d %q, want %q", c.name, c.method, c.target, recorder.Code, recorder.Body.String(), c.want)
+		}
+	}
+}
+
+func TestGatewayRejectsPathsOutsideUpstream(t *testing.T) {
+	b := newEcho("b")
+	defer b.Close()
+	gateway, err := New(Route{ID: "escape", Pattern: "/up/{name}", Upstream: b.URL + "/inner", Rewrite: "/../{name}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	gateway.ServeHTTP(recorder, httptest.NewRequest("GET", "/up/admin", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("GET /up/admin = %d %q, want %d", recorder.Code, recorder.Body.String(), http.StatusBadRequest)
+	}
+}
+
+func TestGatewayStatus(t *testing.T) {
+	gateway, err := New(Route{ID: "down", Pattern: "/only", Upstream: "http://127.0.0.1:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for target, want := range map[string]int{"/nope": http.StatusNotFound, "/only": http.StatusBadGateway} {
+		recorder := httptest.NewRecorder()
+		gateway.ServeHTTP(recorder, httptest.NewRequest("GET", target, nil))
+		if recorder.Code != want {
+			t.Errorf("GET %s = %d, want %d", target, recorder.Code, want)
+		}
+	}
+}
+
+func TestGatewayInvalidRoutes(t *testing.T) {
+	cases := []Route{
+		{ID: "rest-not-last", Pattern: "/a/{*x}/{b}", Upstream: "http://upstream"},
+		{ID: "rest-partial", Pattern: "/a/x{*y}", Upstream: "http://upstream"},
+		{ID: "rest-twice", Pattern: "/a/{*x}/{*y}", Upstream: "http://upstream"},
+		{ID: "rest-name", Pattern: "/a/{*1x}", Upstream: "http://upstream"},
+		{ID: "rest-empty", Pattern: "/a/{*}", Upstream: "http://upstream"},
+		{ID: "undeclared", Pattern: "/x/{a}", Upstream: "http://upstream", Rewrite: "/{b}"},
+		{ID: "relative", Pattern: "/x", Upstream: "upstream"},
+		{ID: "both", Pattern: "/x/**", Upstream: "http://upstream", Rewrite: "/y", StripPrefix: true},
+	}
+	for _, r := range cases {
+		if _, err := New(r); err == nil {
+			t.Errorf("route %q was accepted", r.ID)
+		}
+	}
+}
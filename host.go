@@ -0,0 +1,263 @@
+package antstyle
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	HostSeparator = "." // 主机名标签的分隔符
+	portSeparator = ":"
+	acePrefix     = "xn--" // IDNA中以punycode编码的标签的前缀
+)
+
+// hostPattern 预处理后的主机模式
+type hostPattern struct {
+	reversed string   // 标签顺序反转后的模式，例如"*.example.com"为"com.example.*"
+	port     string   // 端口模式，例如"8080"、"*"或"{port}"
+	hasPort  bool     // 模式中是否指定了端口
+	names    []string // 主机部分的变量名，按原模式中从左到右的顺序
+}
+
+// HostMatcher 匹配主机名与端口的匹配器（线程安全）
+/**
+ *模式与主机名都按"."切分为标签后反转顺序再进行匹配，因此"*.example.com"相当于前缀模式"com.example.*"，
+ *"**.example.com"匹配example.com的任意深度的子域名。匹配不区分大小写，
+ *字面量标签中的非ASCII字符在匹配前以punycode编码（例如"bücher"为"xn--bcher-kva"），
+ *因此Unicode与ASCII形式的主机名可以互相匹配。
+ *模式没有指定端口时只匹配不带端口的主机，指定了端口时只匹配带有相应端口的主机，只有":*"也匹配不带端口的主机。
+ *端口模式可以是":8080"、":80*"或":{port}"。
+ */
+type HostMatcher struct {
+	ant   *AntPathMatcher
+	cache PatternCache
+}
+
+// NewHostMatcher 构造函数
+func NewHostMatcher() *HostMatcher {
+	ant := NewS(HostSeparator)
+	ant.SetCaseSensitive(false)
+	return &HostMatcher{ant: ant, cache: NewMapPatternCache()}
+}
+
+// Match 判断主机是否与模式匹配
+/**
+ *@param pattern 主机模式，例如"*.example.com"、"{tenant}.api.example.com:*"
+ *@param host 主机，可以带端口，例如"Shop.Example.com:8443"或"[::1]:80"
+ *@return bool 是否匹配
+ */
+func (matcher *HostMatcher) Match(pattern, host string) bool {
+	return matcher.match(pattern, host, nil)
+}
+
+// ExtractParams 匹配主机并按模式中出现的顺序提取变量，不匹配时返回false
+func (matcher *HostMatcher) ExtractParams(pattern, host string, params *Params) bool {
+	*params = (*params)[:0]
+	if !matcher.match(pattern, host, params) {
+		*params = (*params)[:0]
+		return false
+	}
+	return true
+}
+
+// match 匹配主机与端口，params不为nil时提取变量
+func (matcher *HostMatcher) match(pattern, host string, params *Params) bool {
+	compiled := matcher.compile(pattern)
+	name, port, hasPort := splitHostPort(host)
+	if hasPort != compiled.hasPort && !(compiled.hasPort && compiled.port == "*") {
+		return false
+	}
+	labels := strings.Split(toASCIIHost(strings.TrimSuffix(name, HostSeparator)), HostSeparator)
+	reverseStrings(labels)
+	if params == nil {
+		return matcher.ant.Match(compiled.reversed, strings.Join(labels, HostSeparator)) &&
+			(!hasPort || matcher.ant.Match(compiled.port, port))
+	}
+	reversedParams := make(Params, 0)
+	if !matcher.ant.ExtractParams(compiled.reversed, strings.Join(labels, HostSeparator), &reversedParams) {
+		return false
+	}
+	// 反转后的变量按标签从右到左排列，按变量名恢复为模式中从左到右的顺序；
+	// 同名的变量出现在不同的标签中时，反转后靠后的一个对应模式中靠前的标签
+	used := make([]bool, len(reversedParams))
+	for _, name := range compiled.names {
+		for i := len(reversedParams) - 1; i >= 0; i-- {
+			if !used[i] && reversedParams[i].Key == name {
+				used[i] = true
+				*params = append(*params, reversedParams[i])
+				break
+			}
+		}
+	}
+	if hasPort {
+		portParams := make(Params, 0)
+		if !matcher.ant.ExtractParams(compiled.port, port, &portParams) {
+			return false
+		}
+		*params = append(*params, portParams...)
+	}
+	return true
+}
+
+// compile 返回缓存的主机模式
+func (matcher *HostMatcher) compile(pattern string) *hostPattern {
+	key := PatternKey{Pattern: pattern}
+	if value, ok := matcher.cache.Get(key); ok {
+		return value.(*hostPattern)
+	}
+	compiled := &hostPattern{}
+	name, port, hasPort := splitHostPort(pattern)
+	compiled.port, compiled.hasPort = port, hasPort
+	labels := strings.Split(toASCIIHost(strings.TrimSuffix(name, HostSeparator)), HostSeparator)
+	for _, label := range labels {
+		for _, token := range parseSegment(label).tokens {
+			if token.kind == regexToken || (token.kind == starToken && token.name != "") {
+				compiled.names = append(compiled.names, token.name)
+			}
+		}
+	}
+	reverseStrings(labels)
+	compiled.reversed = strings.Join(labels, HostSeparator)
+	matcher.cache.Put(key, compiled)
+	return compiled
+}
+
+// splitHostPort 拆分主机与端口，支持"[::1]:80"形式的IPv6地址，不带方括号的IPv6地址视为没有端口
+func splitHostPort(host string) (string, string, bool) {
+	if strings.HasPrefix(host, "[") {
+		end := strings.Index(host, "]")
+		if end != -1 && strings.HasPrefix(host[end+1:], portSeparator) {
+			return host[:end+1], host[end+2:], true
+		}
+		return host, "", false
+	}
+	idx := strings.Index(host, portSeparator)
+	if idx == -1 || strings.Count(host, portSeparator) > 1 {
+		return host, "", false
+	}
+	return host[:idx], host[idx+1:], true
+}
+
+// toASCIIHost 将主机名转为小写，并以punycode编码含有非ASCII字符的字面量标签
+func toASCIIHost(host string) string {
+	host = strings.ToLower(host)
+	if isASCII(host) {
+		return host
+	}
+	labels := strings.Split(host, HostSeparator)
+	for i, label := range labels {
+		if !isASCII(label) && isLiteralSegment(label) {
+			labels[i] = acePrefix + punycodeEncode(label)
+		}
+	}
+	return strings.Join(labels, HostSeparator)
+}
+
+// isASCII 字符串是否只含ASCII字符
+func isASCII(str string) bool {
+	for i := 0; i < len(str); i++ {
+		if str[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// reverseStrings 原地反转切片
+func reverseStrings(values []string) {
+	for i, j := 0, len(values)-1; i < j; i, j = i+1, j-1 {
+		values[i], values[j] = values[j], values[i]
+	}
+}
+
+// punycode的参数，见RFC 3492第5节
+const (
+	punycodeBase        = 36
+	punycodeTMin        = 1
+	punycodeTMax        = 26
+	punycodeSkew        = 38
+	punycodeDamp        = 700
+	punycodeInitialBias = 72
+	punycodeInitialN    = 128
+)
+
+// punycodeEncode 按RFC 3492将标签编码为punycode，不含"xn--"前缀
+func punycodeEncode(label string) string {
+	runes := []rune(label)
+	output := make([]byte, 0, len(label)+8)
+	for _, r := range runes {
+		if r < utf8.RuneSelf {
+			output = append(output, byte(r))
+		}
+	}
+	basic := len(output)
+	handled := basic
+	if basic > 0 {
+		output = append(output, '-')
+	}
+	n, delta, bias := rune(punycodeInitialN), 0, punycodeInitialBias
+	for handled < len(runes) {
+		// 下一个要编码的最小码点
+		m := rune(utf8.MaxRune + 1)
+		for _, r := range runes {
+			if r >= n && r < m {
+				m = r
+			}
+		}
+		delta += int(m-n) * (handled + 1)
+		n = m
+		for _, r := range runes {
+			if r < n {
+				delta++
+			}
+			if r != n {
+				continue
+			}
+			q := delta
+			for k := punycodeBase; ; k += punycodeBase {
+				t := k - bias
+				if t < punycodeTMin {
+					t = punycodeTMin
+				} else if t > punycodeTMax {
+					t = punycodeTMax
+				}
+				if q < t {
+					break
+				}
+				output = append(output, punycodeDigit(t+(q-t)%(punycodeBase-t)))
+				q = (q - t) / (punycodeBase - t)
+			}
+			output = append(output, punycodeDigit(q))
+			bias = punycodeAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+		delta++
+		n++
+	}
+	return string(output)
+}
+
+// punycodeAdapt 调整偏差，见RFC 3492第6.1节
+func punycodeAdapt(delta, numPoints int, firstTime bool) int {
+	if firstTime {
+		delta /= punycodeDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punycodeBase-punycodeTMin)*punycodeTMax)/2 {
+		delta /= punycodeBase - punycodeTMin
+		k += punycodeBase
+	}
+	return k + (punycodeBase-punycodeTMin+1)*delta/(delta+punycodeSkew)
+}
+
+// punycodeDigit 将0到35的数字编码为"a"到"z"与"0"到"9"
+func punycodeDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
@@ -0,0 +1,84 @@
+package antstyle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHostExtractParamsKeepsPatternOrder(t *testing.T) {
+	cases := []struct {
+		pattern string
+		host    string
+		want    Params
+	}{
+		{"{sub}.example.com", "api.example.com", Params{{"sub", "api"}}},
+		{"{a}.{b}.**.com", "x.y.z.com", Params{{"a", "x"}, {"b", "y"}}},
+		{"{a}.**.{b}.com", "x.y.z.com", Params{{"a", "x"}, {"b", "z"}}},
+		{"**.{a}.{b}.com", "w.x.y.com", Params{{"a", "x"}, {"b", "y"}}},
+		{"{a}-{c}.**.{b}.com:{port}", "x-q.y.z.com:80", Params{{"a", "x"}, {"c", "q"}, {"b", "z"}, {"port", "80"}}},
+	}
+	matcher := NewHostMatcher()
+	for _, c := range cases {
+		params := make(Params, 0)
+		if !matcher.ExtractParams(c.pattern, c.host, &params) {
+			t.Errorf("ExtractParams(%q, %q) did not match", c.pattern, c.host)
+			continue
+		}
+		if !reflect.DeepEqual(params, c.want) {
+			t.Errorf("ExtractParams(%q, %q) = %v, want %v", c.pattern, c.host, params, c.want)
+		}
+	}
+}
+
+func TestHostMatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		host    string
+		want    bool
+	}{
+		{"*.example.com", "api.example.com", true},
+		{"*.example.com", "a.b.example.com", false},
+		{"*.example.com", "example.com", false},
+		{"**.example.com", "example.com", true},
+		{"**.example.com", "api.example.com", true},
+		{"**.example.com", "a.b.example.com", true},
+		{"**.example.com", "badexample.com", false},
+		{"**.example.com", "example.com.evil.org", false},
+		{"*.example.com", "example.com.evil.org", false},
+		{"api.*.com", "api.example.com", true},
+		{"*.example.com", "API.Example.COM", true},
+		{"*.example.com", "api.example.com.", true},
+		{"{tenant}.api.example.com", "acme.api.example.com", true},
+		{"{tenant}.api.example.com", "api.example.com", false},
+		{"*.example.com", "api.example.com:8080", false},
+		{"*.example.com:8080", "api.example.com:8080", true},
+		{"*.example.com:8080", "api.example.com:8081", false},
+		{"*.example.com:8080", "api.example.com", false},
+		{"*.example.com:*", "api.example.com", true},
+		{"*.example.com:*", "api.example.com:443", true},
+		{"*.example.com:80*", "api.example.com:8080", true},
+		{"*.example.com:80*", "api.example.com:443", false},
+		{"[::1]:*", "[::1]:80", true},
+		{"bücher.example.com", "xn--bcher-kva.example.com", true},
+		{"xn--bcher-kva.example.com", "Bücher.example.com", true},
+		{"*.bücher.de", "shop.xn--bcher-kva.de", true},
+	}
+	matcher := NewHostMatcher()
+	for _, c := range cases {
+		if got := matcher.Match(c.pattern, c.host); got != c.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", c.pattern, c.host, got, c.want)
+		}
+	}
+}
+
+func TestHostExtractParamsNoMatch(t *testing.T) {
+	matcher := NewHostMatcher()
+	params := Params{{"stale", "value"}}
+	if matcher.ExtractParams("{tenant}.example.com:{port}", "a.b.example.com:80", &params) || len(params) != 0 {
+		t.Errorf("ExtractParams of unmatched host = %v", params)
+	}
+	if !matcher.ExtractParams("{tenant}.**.example.com", "acme.eu.west.example.com", &params) ||
+		!reflect.DeepEqual(params, Params{{"tenant", "acme"}}) {
+		t.Errorf("ExtractParams with ** = %v", params)
+	}
+}
@@ -0,0 +1,67 @@
+package antstyle
+
+import (
+	"strings"
+
+	"github.com/aluka-7/utils"
+)
+
+// MatchSegments 将已切分的路径段与模式进行匹配
+/**
+ *结果与Match(pattern, path)相同，其中path由segments以路径分隔符连接而成，
+ *模式以分隔符开头时path也以分隔符开头，且path不以分隔符结尾。
+ *例如模式"/api/*"与segments ["api", "users"]相当于路径"/api/users"。
+ *段已经是切分后的形式时直接匹配，不再拼接和切分字符串；
+ *含有空段、含有分隔符或需要去除空格时，按拼接后的路径匹配。
+ *@param pattern 要匹配的模式
+ *@param segments 路径段
+ *@return bool 是否匹配
+ */
+func (ant *AntPathMatcher) MatchSegments(pattern string, segments []string) bool {
+	compiled := ant.compilePattern(pattern)
+	if compiled.err != nil {
+		return false
+	}
+	length := 0
+	for _, segment := range segments {
+		if segment == utils.EmptyString || ant.trimTokens || strings.Contains(segment, ant.pathSeparator) {
+			return ant.matchJoined(compiled, segments)
+		}
+		length += len(segment)
+	}
+	if len(segments) > 0 {
+		length += len(ant.pathSeparator) * (len(segments) - 1)
+	}
+	if compiled.leading {
+		length += len(ant.pathSeparator)
+	}
+	limits := &ant.limits
+	if (limits.MaxPathLength > 0 && length > limits.MaxPathLength) || (limits.MaxSegments > 0 && len(segments) > limits.MaxSegments) {
+		return false
+	}
+	// 没有任何段时路径只剩开头的分隔符，此时它同时也是结尾的分隔符
+	return ant.matchSegments(compiled, segments, compiled.leading && len(segments) == 0, true, nil)
+}
+
+// matchJoined 将路径段拼接为路径后进行匹配
+func (ant *AntPathMatcher) matchJoined(compiled *compiledPattern, segments []string) bool {
+	path := strings.Join(segments, ant.pathSeparator)
+	if compiled.leading {
+		path = ant.pathSeparator + path
+	}
+	matched, _ := ant.tryMatch(compiled.pattern, path, true, nil)
+	return matched
+}
+
+// MatchBytes 将字节切片形式的路径与模式进行匹配
+/**
+ *结果与Match(pattern, string(path))相同，但不复制path。
+ *path在匹配期间不能被修改；结果不会写入结果缓存，因为缓存的键不能引用调用方的内存。
+ *@param pattern 要匹配的模式
+ *@param path 要测试的路径
+ *@return bool 是否匹配
+ */
+func (ant *AntPathMatcher) MatchBytes(pattern string, path []byte) bool {
+	matched, _ := ant.tryMatch(pattern, utils.Bytes2Str(path), true, nil)
+	return matched
+}
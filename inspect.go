@@ -0,0 +1,74 @@
+package antstyle
+
+import "strings"
+
+// SegmentDescription 模式中的一段
+type SegmentDescription struct {
+	Text string      // 段的原始文本
+	Kind SegmentKind // 段的种类
+}
+
+// VariableDescription 模式中的一个URI模板变量
+type VariableDescription struct {
+	Name    string // 变量名
+	Regex   string // {name:regex}中的约束表达式，不带约束时为空字符串
+	Segment int    // 变量所在段在Segments中的下标
+}
+
+// PatternDescription 由Inspect返回的模式结构描述
+type PatternDescription struct {
+	Pattern         string
+	Segments        []SegmentDescription
+	Variables       []VariableDescription
+	LiteralPrefix   string // 第一个通配符或变量之前的最长字面量前缀
+	SingleWildcards int    // 段内不带名称的"*"的个数
+	DoubleWildcards int    // "**"段的个数
+	UriVars         int    // URI模板变量的个数
+	CatchAll        bool   // 是否只由"**"段组成，例如"/**"，能匹配任意路径
+	PrefixPattern   bool   // 是否为以分隔符加"**"结尾的前缀模式，例如"/static/**"
+}
+
+// Inspect 返回模式的结构描述
+/**
+ *将模式按路径分隔符切分，为每一段标注字面量、通配、变量或"**"，
+ *并给出变量名及其约束、最长字面量前缀，以及按段统计的通配符与变量个数。
+ *@param pattern 要描述的模式
+ *@return PatternDescription 模式的结构描述
+ */
+func (ant *AntPathMatcher) Inspect(pattern string) PatternDescription {
+	description := PatternDescription{
+		Pattern:       pattern,
+		Segments:      make([]SegmentDescription, 0),
+		Variables:     make([]VariableDescription, 0),
+		LiteralPrefix: pattern,
+	}
+	if idx := strings.IndexAny(pattern, string(WildcardChars)); idx != -1 {
+		description.LiteralPrefix = pattern[:idx]
+	}
+	for _, token := range ant.tokenizePath(pattern) {
+		segment := parseSegment(*token)
+		if segment.kind == DoubleWildcardSegment {
+			description.DoubleWildcards++
+		}
+		for _, t := range segment.tokens {
+			if t.kind == starToken && t.name == "" {
+				description.SingleWildcards++
+			}
+			if t.kind == regexToken || (t.kind == starToken && t.name != "") {
+				description.Variables = append(description.Variables, VariableDescription{
+					Name:    t.name,
+					Regex:   t.regex,
+					Segment: len(description.Segments),
+				})
+			}
+		}
+		description.Segments = append(description.Segments, SegmentDescription{Text: segment.text, Kind: segment.kind})
+	}
+	description.UriVars = len(description.Variables)
+	count := len(description.Segments)
+	if count > 0 && description.Segments[count-1].Kind == DoubleWildcardSegment && !strings.HasSuffix(pattern, ant.pathSeparator) {
+		description.CatchAll = description.DoubleWildcards == count
+		description.PrefixPattern = !description.CatchAll
+	}
+	return description
+}
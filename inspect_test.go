@@ -0,0 +1,73 @@
+package antstyle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	description := New().Inspect("/api/v{version:\\d+}/*/**/{id}.json")
+	want := PatternDescription{
+		Pattern: "/api/v{version:\\d+}/*/**/{id}.json",
+		Segments: []SegmentDescription{
+			{"api", LiteralSegment},
+			{"v{version:\\d+}", VariableSegment},
+			{"*", GlobSegment},
+			{"**", DoubleWildcardSegment},
+			{"{id}.json", VariableSegment},
+		},
+		Variables: []VariableDescription{
+			{Name: "version", Regex: "\\d+", Segment: 1},
+			{Name: "id", Segment: 4},
+		},
+		LiteralPrefix:   "/api/v",
+		SingleWildcards: 1,
+		DoubleWildcards: 1,
+		UriVars:         2,
+	}
+	if !reflect.DeepEqual(description, want) {
+		t.Errorf("Inspect = %+v, want %+v", description, want)
+	}
+}
+
+func TestInspectCounts(t *testing.T) {
+	cases := []struct {
+		pattern         string
+		singleWildcards int
+		doubleWildcards int
+		uriVars         int
+		catchAll        bool
+		prefixPattern   bool
+	}{
+		{"", 0, 0, 0, false, false},
+		{"/a/b", 0, 0, 0, false, false},
+		{"/**", 0, 1, 0, true, false},
+		{"/**/**", 0, 2, 0, true, false},
+		{"/**/", 0, 1, 0, false, false},
+		{"/static/**", 0, 1, 0, false, true},
+		{"/static/**/", 0, 1, 0, false, false},
+		{"/**/*.html", 1, 1, 0, false, false},
+		{"/a*b*/?", 2, 0, 0, false, false},
+		{"/{a}/{b:[a-z]*}/x.*", 1, 0, 2, false, false},
+		{"/a**b", 2, 0, 0, false, false},
+	}
+	ant := New()
+	for _, c := range cases {
+		d := ant.Inspect(c.pattern)
+		got := []interface{}{d.SingleWildcards, d.DoubleWildcards, d.UriVars, d.CatchAll, d.PrefixPattern}
+		want := []interface{}{c.singleWildcards, c.doubleWildcards, c.uriVars, c.catchAll, c.prefixPattern}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Inspect(%q) counts = %v, want %v", c.pattern, got, want)
+		}
+	}
+}
+
+func TestInspectCustomSeparator(t *testing.T) {
+	d := NewS(".").Inspect("orders.{region}.#.**")
+	if len(d.Segments) != 4 || d.LiteralPrefix != "orders." || !d.PrefixPattern || d.UriVars != 1 {
+		t.Errorf("Inspect = %+v", d)
+	}
+	if d := NewS(".").Inspect("**"); !d.CatchAll {
+		t.Errorf("Inspect(\"**\") with \".\" separator is not a catch-all: %+v", d)
+	}
+}
@@ -0,0 +1,91 @@
+package antstyle
+
+import (
+	"fmt"
+	"regexp/syntax"
+)
+
+// Limits 处理不可信的模式与路径时的复杂度上限，值为0表示不限制
+/**
+ *模式相关的限制在模式第一次被编译时检查，路径相关的限制在每次匹配时检查。
+ *超出限制时Match返回false，TryMatch与ValidatePattern返回*LimitError，而不会继续消耗CPU。
+ */
+type Limits struct {
+	MaxPatternLength    int // 模式的最大长度（字节）
+	MaxSegments         int // 模式与路径的最大段数
+	MaxDoubleWildcards  int // 模式中"**"的最大个数
+	MaxVariables        int // 模式中URI模板变量的最大个数
+	MaxRegexProgramSize int // 单个{name:regex}约束经regexp/syntax编译后的最大指令数
+	MaxPathLength       int // 路径的最大长度（字节）
+}
+
+// UntrustedLimits 适用于由租户配置的模式的一组限制
+var UntrustedLimits = Limits{
+	MaxPatternLength:    1024,
+	MaxSegments:         64,
+	MaxDoubleWildcards:  4,
+	MaxVariables:        16,
+	MaxRegexProgramSize: 256,
+	MaxPathLength:       4096,
+}
+
+// LimitError 模式或路径超出Limits时返回的错误
+type LimitError struct {
+	Limit string // 被超出的限制，例如"MaxPatternLength"
+	Value int    // 实际的值
+	Max   int    // 允许的最大值
+	Input string // 超出限制的模式或路径
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("antstyle: %s exceeded (%d > %d) for %q", e.Limit, e.Value, e.Max, e.Input)
+}
+
+// checkLimit 当value超过非零的max时返回LimitError
+func checkLimit(limit string, value, max int, input string) error {
+	if max > 0 && value > max {
+		return &LimitError{Limit: limit, Value: value, Max: max, Input: input}
+	}
+	return nil
+}
+
+// checkPattern 检查已解析的模式段是否超出限制
+func (limits *Limits) checkPattern(pattern string, segments []*patternSegment) error {
+	if err := checkLimit("MaxSegments", len(segments), limits.MaxSegments, pattern); err != nil {
+		return err
+	}
+	doubleWildcards, variables := 0, 0
+	for _, segment := range segments {
+		if segment.kind == DoubleWildcardSegment {
+			doubleWildcards++
+		}
+		for _, token := range segment.tokens {
+			if token.name == "" && token.kind != regexToken {
+				continue
+			}
+			variables++
+			if token.kind == regexToken && limits.MaxRegexProgramSize > 0 {
+				if err := checkLimit("MaxRegexProgramSize", regexProgramSize(token.regex), limits.MaxRegexProgramSize, pattern); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := checkLimit("MaxDoubleWildcards", doubleWildcards, limits.MaxDoubleWildcards, pattern); err != nil {
+		return err
+	}
+	return checkLimit("MaxVariables", variables, limits.MaxVariables, pattern)
+}
+
+// regexProgramSize 返回正则表达式编译后的指令数，无法解析的表达式返回0，由compilePattern报告编译错误
+func regexProgramSize(expr string) int {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return 0
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return 0
+	}
+	return len(prog.Inst)
+}
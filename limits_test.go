@@ -0,0 +1,24 @@
+package antstyle
+
+import "testing"
+
+func TestInvalidRegexReportsError(t *testing.T) {
+	for _, limits := range []Limits{{}, UntrustedLimits} {
+		ant := New()
+		ant.SetLimits(limits)
+		pattern := "/a/{id:[}"
+		if err := ant.ValidatePattern(pattern); err == nil {
+			t.Errorf("ValidatePattern(%q) with %+v returned nil", pattern, limits)
+		}
+		if matched, err := ant.TryMatch(pattern, "/a/1"); matched || err == nil {
+			t.Errorf("TryMatch(%q) = %v, %v", pattern, matched, err)
+		}
+		if ant.Match(pattern, "/a/1") {
+			t.Errorf("Match(%q) matched", pattern)
+		}
+		params := make(Params, 0)
+		if ant.ExtractParams(pattern, "/a/1", &params) {
+			t.Errorf("ExtractParams(%q) matched", pattern)
+		}
+	}
+}
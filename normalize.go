@@ -0,0 +1,104 @@
+package antstyle
+
+import (
+	"strings"
+
+	"github.com/aluka-7/utils"
+)
+
+const (
+	currentSegment = "."  // 当前目录段
+	parentSegment  = ".." // 上级目录段
+)
+
+// Normalize 返回给定模式的规范形式
+//
+// 规范化会合并重复的路径分隔符("/a//b/" -> "/a/b/")，合并相邻的"**"("/**/**/x" -> "/**/x")，
+// 解析字面量段中的"."与".."("/a/./b" -> "/a/b"，"/a/b/../c" -> "/a/c")，
+// 并把由"*"与"**"组成的连续段统一写成"**"在前、"*"在后("/*/**" -> "/**/*")。
+// 开头的路径分隔符会被保留；结尾的路径分隔符只在模式不含"**"时保留，因为只有这时doMatch才会比较它，
+// 含有"**"时"/a/**/"与"/a/**"、"/a/**/b/"与"/a/**/b"匹配相同的路径，规范形式中不含结尾的分隔符。
+// 除"."与".."的解析外，规范化前后的模式匹配相同的路径；对"."与".."的解析假设被匹配的路径同样已经规范化。
+// 返回值可用于路由去重或作为缓存键。
+func (ant *AntPathMatcher) Normalize(pattern string) string {
+	if !utils.HasText(pattern) {
+		return pattern
+	}
+	segments := make([]string, 0)
+	for _, token := range ant.tokenizePath(pattern) {
+		segments = append(segments, *token)
+	}
+	segments = collapseWildcardRuns(resolveDotSegments(segments))
+
+	builder := strings.Builder{}
+	if strings.HasPrefix(pattern, ant.pathSeparator) {
+		builder.WriteString(ant.pathSeparator)
+	}
+	builder.WriteString(strings.Join(segments, ant.pathSeparator))
+	if len(segments) > 0 && strings.HasSuffix(pattern, ant.pathSeparator) && !containsString(segments, "**") {
+		builder.WriteString(ant.pathSeparator)
+	}
+	return builder.String()
+}
+
+// resolveDotSegments 去掉"."段，并让".."抵消前一个字面量段
+func resolveDotSegments(segments []string) []string {
+	resolved := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		switch segment {
+		case currentSegment:
+			continue
+		case parentSegment:
+			last := len(resolved) - 1
+			if last >= 0 && isLiteralSegment(resolved[last]) {
+				resolved = resolved[:last]
+				continue
+			}
+		}
+		resolved = append(resolved, segment)
+	}
+	return resolved
+}
+
+// collapseWildcardRuns 将只由"*"与"**"组成的连续段改写为规范形式
+// 这样的连续段匹配"至少k个段"(k为"*"的个数)，只要其中出现过"**"，写成一个"**"后接k个"*"即可表达相同的含义。
+func collapseWildcardRuns(segments []string) []string {
+	collapsed := make([]string, 0, len(segments))
+	for i := 0; i < len(segments); {
+		if segments[i] != "*" && segments[i] != "**" {
+			collapsed = append(collapsed, segments[i])
+			i++
+			continue
+		}
+		singles, doubles := 0, 0
+		for ; i < len(segments) && (segments[i] == "*" || segments[i] == "**"); i++ {
+			if segments[i] == "**" {
+				doubles++
+			} else {
+				singles++
+			}
+		}
+		if doubles > 0 {
+			collapsed = append(collapsed, "**")
+		}
+		for ; singles > 0; singles-- {
+			collapsed = append(collapsed, "*")
+		}
+	}
+	return collapsed
+}
+
+// containsString 切片中是否含有给定的字符串
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isLiteralSegment 判断段中是否不含任何通配符
+func isLiteralSegment(segment string) bool {
+	return segment != parentSegment && strings.IndexAny(segment, string(WildcardChars)) == -1
+}
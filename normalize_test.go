@@ -0,0 +1,49 @@
+package antstyle
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		pattern string
+		want    string
+	}{
+		{"", ""},
+		{"/", "/"},
+		{"/a//b/", "/a/b/"},
+		{"a/b", "a/b"},
+		{"/a/b/", "/a/b/"},
+		{"/**/**/x", "/**/x"},
+		{"/a/./b", "/a/b"},
+		{"/a/b/../c", "/a/c"},
+		{"/*/../c", "/*/../c"},
+		{"/*/**", "/**/*"},
+		{"/*/**/*/x", "/**/*/*/x"},
+		{"/a/**/", "/a/**"},
+		{"/a/**", "/a/**"},
+		{"/a/**/b/", "/a/**/b"},
+		{"/a/**/b", "/a/**/b"},
+		{"/a/*/", "/a/*/"},
+	}
+	ant := New()
+	for _, c := range cases {
+		if got := ant.Normalize(c.pattern); got != c.want {
+			t.Errorf("Normalize(%q) = %q, want %q", c.pattern, got, c.want)
+		}
+	}
+}
+
+func TestNormalizeKeepsMatchedPaths(t *testing.T) {
+	patterns := []string{"/a/**/", "/a/**/b/", "/a//b/", "/*/**", "/**/**/x", "/a/*/", "/*/**/*/x/"}
+	ant := New()
+	for _, pattern := range patterns {
+		normalized := ant.Normalize(pattern)
+		if !ant.Covers(pattern, normalized) || !ant.Covers(normalized, pattern) {
+			t.Errorf("Normalize(%q) = %q does not match the same paths", pattern, normalized)
+		}
+	}
+	for _, pair := range [][2]string{{"/a/**/", "/a/**"}, {"/a/**/b/", "/a/**/b"}} {
+		if ant.Normalize(pair[0]) != ant.Normalize(pair[1]) {
+			t.Errorf("Normalize(%q) != Normalize(%q)", pair[0], pair[1])
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package antstyle
+
+import (
+	"sort"
+	"sync"
+)
+
+// Param 一个URI模板变量及其取值
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Params 按变量在模式中出现的顺序排列的URI模板变量
+/**
+ *与map不同，Params可以由调用方重复使用，提取变量时只向已有的空间追加，不会为每次匹配分配新的map。
+ */
+type Params []Param
+
+// ByName 返回第一个名为name的变量的值，没有时返回空字符串
+func (ps Params) ByName(name string) string {
+	for _, p := range ps {
+		if p.Key == name {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// ByIndex 返回第i个变量的值，下标越界时返回空字符串
+func (ps Params) ByIndex(i int) string {
+	if i < 0 || i >= len(ps) {
+		return ""
+	}
+	return ps[i].Value
+}
+
+// ToMap 将变量转换为map，变量名重复时后出现的值覆盖先出现的值
+func (ps Params) ToMap() map[string]string {
+	variables := make(map[string]string, len(ps))
+	for _, p := range ps {
+		variables[p.Key] = p.Value
+	}
+	return variables
+}
+
+var paramsPool = sync.Pool{
+	New: func() interface{} {
+		ps := make(Params, 0, 8)
+		return &ps
+	},
+}
+
+// AcquireParams 从池中取出一个空的Params，使用完毕后应调用ReleaseParams归还
+func AcquireParams() *Params {
+	return paramsPool.Get().(*Params)
+}
+
+// ReleaseParams 将Params清空后归还到池中，归还后不能再使用其中的值
+func ReleaseParams(ps *Params) {
+	*ps = (*ps)[:0]
+	paramsPool.Put(ps)
+}
+
+// ExtractParams 匹配路径并按顺序提取URI模板变量
+/**
+ *与ExtractUriTemplateVariables相同，但变量按它们在模式中出现的顺序写入调用方提供的params，
+ *params原有的内容会被清空。不匹配时返回false，params为空，而不是panic。
+ *@param pattern 模式路径模式，可能包含URI模板
+ *@param path 从中提取模板变量的完整路径
+ *@param params 用于保存变量的Params，可以由AcquireParams取得
+ *@return bool 是否匹配
+ */
+func (ant *AntPathMatcher) ExtractParams(pattern, path string, params *Params) bool {
+	*params = (*params)[:0]
+	matched, _ := ant.cachedMatch(pattern, path, true, params)
+	if !matched {
+		*params = (*params)[:0]
+	}
+	return matched
+}
+
+// ParamsExtractor 能按顺序提取URI模板变量的PathMatcher，由AntPathMatcher实现
+/**
+ *PathMatcher接口本身只提供ExtractUriTemplateVariables。Registry、RouteGroup等组件在matcher实现了本接口时使用ExtractParams，
+ *否则在匹配后将ExtractUriTemplateVariables返回的变量按名称排序写入Params。
+ */
+type ParamsExtractor interface {
+	ExtractParams(pattern, path string, params *Params) bool
+}
+
+// extractParams 使用给定的PathMatcher匹配路径并提取变量，不匹配时返回false
+func extractParams(matcher PathMatcher, pattern, path string, params *Params) bool {
+	if extractor, ok := matcher.(ParamsExtractor); ok {
+		return extractor.ExtractParams(pattern, path, params)
+	}
+	*params = (*params)[:0]
+	if !matcher.Match(pattern, path) {
+		return false
+	}
+	variables := *matcher.ExtractUriTemplateVariables(pattern, path)
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		*params = append(*params, Param{Key: name, Value: variables[name]})
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package antstyle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractParamsKeepsPatternOrder(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		keys    []string
+	}{
+		{"/{a}/**/{b}/{c}", "/1/x/2/3", []string{"a", "b", "c"}},
+		{"/{a}/{b}/**/{c}/{d}", "/1/2/x/y/3/4", []string{"a", "b", "c", "d"}},
+		{"/{a}/**/{b}/**/{c}", "/1/x/2/y/3", []string{"a", "b", "c"}},
+		{"/**/{b}/{c}", "/2/3", []string{"b", "c"}},
+	}
+	ant := New()
+	for _, c := range cases {
+		params := make(Params, 0)
+		if !ant.ExtractParams(c.pattern, c.path, &params) {
+			t.Fatalf("ExtractParams(%q, %q) did not match", c.pattern, c.path)
+		}
+		keys := make([]string, 0, len(params))
+		for _, p := range params {
+			keys = append(keys, p.Key)
+		}
+		if !reflect.DeepEqual(keys, c.keys) {
+			t.Errorf("ExtractParams(%q, %q) keys = %v, want %v", c.pattern, c.path, keys, c.keys)
+		}
+		for i, key := range c.keys {
+			if params.ByIndex(i) != params.ByName(key) {
+				t.Errorf("ByIndex(%d) = %q, ByName(%q) = %q", i, params.ByIndex(i), key, params.ByName(key))
+			}
+		}
+	}
+}
+
+func TestResultCacheHitsWithoutVariables(t *testing.T) {
+	ant := New()
+	ant.SetResultCacheSize(16)
+	var params Params
+	for i := 0; i < 3; i++ {
+		if !ant.ExtractParams("/static/**", "/static/a.css", &params) {
+			t.Fatal("ExtractParams did not match")
+		}
+	}
+	if stats := ant.GetResultCacheStats(); stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("stats = %+v, want 2 hits and 1 miss", stats)
+	}
+}
@@ -0,0 +1,89 @@
+package antstyle
+
+import "sync"
+
+// PatternKey 模式缓存的键
+/**
+ *除模式本身外，键中还包含影响预处理结果的配置，
+ *因此修改AntPathMatcher的设置后不会再取到按旧配置创建的条目，配置不同的AntPathMatcher也可以共享同一个缓存。
+ *单个模式段的匹配器只与大小写设置有关，其键中其余的配置为零值。
+ */
+type PatternKey struct {
+	Pattern       string
+	PathSeparator string
+	CaseSensitive bool
+	TrimTokens    bool
+	Limits        Limits
+}
+
+// PatternCache AntPathMatcher用于缓存预处理结果的缓存（需要线程安全）
+/**
+ *AntPathMatcher使用两个缓存：一个保存整个模式的预处理结果，一个保存单个模式段的AntPathStringMatcher。
+ *可以通过NewWithCache传入自定义的实现，例如在多个AntPathMatcher之间共享缓存，或使用有界的缓存。
+ *Get返回的值只能是之前由同一个缓存的Put保存的值。
+ */
+type PatternCache interface {
+	Get(key PatternKey) (interface{}, bool)
+	Put(key PatternKey, value interface{})
+	Len() int
+	Clear()
+}
+
+// MapPatternCache 基于map与读写锁的无界缓存，是AntPathMatcher默认使用的实现
+type MapPatternCache struct {
+	mutex   sync.RWMutex
+	entries map[PatternKey]interface{}
+}
+
+// NewMapPatternCache 构造函数
+func NewMapPatternCache() *MapPatternCache {
+	return &MapPatternCache{entries: make(map[PatternKey]interface{})}
+}
+
+// Get 返回缓存的值
+func (cache *MapPatternCache) Get(key PatternKey) (interface{}, bool) {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	value, ok := cache.entries[key]
+	return value, ok
+}
+
+// Put 保存值
+func (cache *MapPatternCache) Put(key PatternKey, value interface{}) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.entries[key] = value
+}
+
+// Len 返回条目数
+func (cache *MapPatternCache) Len() int {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	return len(cache.entries)
+}
+
+// Clear 清除所有条目
+func (cache *MapPatternCache) Clear() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.entries = make(map[PatternKey]interface{})
+}
+
+// NoopPatternCache 不保存任何内容的缓存，用于关闭某一个缓存
+type NoopPatternCache struct{}
+
+// Get 总是返回未找到
+func (NoopPatternCache) Get(key PatternKey) (interface{}, bool) {
+	return nil, false
+}
+
+// Put 丢弃值
+func (NoopPatternCache) Put(key PatternKey, value interface{}) {}
+
+// Len 总是返回0
+func (NoopPatternCache) Len() int {
+	return 0
+}
+
+// Clear 什么也不做
+func (NoopPatternCache) Clear() {}
@@ -0,0 +1,100 @@
+package antstyle
+
+import (
+	"sort"
+	"sync"
+)
+
+// RegistryMatch Registry.LookupAll返回的一个匹配项
+type RegistryMatch[T any] struct {
+	Pattern string
+	Value   T
+	Params  Params
+}
+
+// registryEntry Registry中注册的模式与值
+type registryEntry[T any] struct {
+	pattern string
+	value   T
+}
+
+// Registry 将模式映射到值的注册表（线程安全）
+/**
+ *类似于Spring的UrlBasedCorsConfigurationSource，可用于按路径查找CORS、缓存头或限流等配置。
+ *查找时使用以字典序作为最终比较的AntPatternComparator，同一条路径总是得到确定的结果。
+ */
+type Registry[T any] struct {
+	matcher PathMatcher
+	mutex   sync.RWMutex
+	entries []registryEntry[T]
+}
+
+// NewRegistry 使用默认的AntPathMatcher创建注册表
+func NewRegistry[T any]() *Registry[T] {
+	return NewRegistryWithMatcher[T](New())
+}
+
+// NewRegistryWithMatcher 使用给定的PathMatcher创建注册表
+func NewRegistryWithMatcher[T any](matcher PathMatcher) *Registry[T] {
+	return &Registry[T]{matcher: matcher}
+}
+
+// Register 注册模式与对应的值，模式已注册时替换原来的值
+func (registry *Registry[T]) Register(pattern string, value T) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	for i := range registry.entries {
+		if registry.entries[i].pattern == pattern {
+			registry.entries[i].value = value
+			return
+		}
+	}
+	registry.entries = append(registry.entries, registryEntry[T]{pattern: pattern, value: value})
+}
+
+// Lookup 返回与路径匹配的最具体的模式对应的值及其URI模板变量
+/**
+ *@param path 要查找的路径
+ *@return T 对应的值，没有匹配时为零值
+ *@return Params 按顺序排列的URI模板变量
+ *@return bool 是否有模式匹配
+ */
+func (registry *Registry[T]) Lookup(path string) (T, Params, bool) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	comparator := NewTotalOrderAntPatternComparator(path)
+	best := -1
+	for i, entry := range registry.entries {
+		if !registry.matcher.Match(entry.pattern, path) {
+			continue
+		}
+		if best == -1 || comparator.Compare(entry.pattern, registry.entries[best].pattern) < 0 {
+			best = i
+		}
+	}
+	if best == -1 {
+		var zero T
+		return zero, nil, false
+	}
+	params := make(Params, 0)
+	extractParams(registry.matcher, registry.entries[best].pattern, path, &params)
+	return registry.entries[best].value, params, true
+}
+
+// LookupAll 返回所有与路径匹配的模式，按从最具体到最通用的顺序排列
+func (registry *Registry[T]) LookupAll(path string) []RegistryMatch[T] {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	matches := make([]RegistryMatch[T], 0)
+	for _, entry := range registry.entries {
+		params := make(Params, 0)
+		if extractParams(registry.matcher, entry.pattern, path, &params) {
+			matches = append(matches, RegistryMatch[T]{Pattern: entry.pattern, Value: entry.value, Params: params})
+		}
+	}
+	comparator := NewTotalOrderAntPatternComparator(path)
+	sort.SliceStable(matches, func(i, j int) bool {
+		return comparator.Compare(matches[i].Pattern, matches[j].Pattern) < 0
+	})
+	return matches
+}
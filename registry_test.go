@@ -0,0 +1,115 @@
+package antstyle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegistryLookup(t *testing.T) {
+	registry := NewRegistry[string]()
+	registry.Register("/**", "default")
+	registry.Register("/api/**", "api")
+	registry.Register("/api/{version}/users/{id}", "user")
+	registry.Register("/api/*/users/*", "users")
+	registry.Register("/api/v1/users/me", "me")
+
+	cases := []struct {
+		path   string
+		value  string
+		params Params
+	}{
+		{"/api/v1/users/me", "me", Params{}},
+		{"/api/v1/users/7", "user", Params{{"version", "v1"}, {"id", "7"}}},
+		{"/api/v1/groups", "api", Params{}},
+		{"/static/site.css", "default", Params{}},
+	}
+	for _, c := range cases {
+		value, params, ok := registry.Lookup(c.path)
+		if !ok || value != c.value || !reflect.DeepEqual(params, c.params) {
+			t.Errorf("Lookup(%q) = %q, %v, %v, want %q, %v", c.path, value, params, ok, c.value, c.params)
+		}
+	}
+}
+
+func TestRegistryLookupMiss(t *testing.T) {
+	registry := NewRegistry[int]()
+	registry.Register("/api/**", 1)
+	if value, params, ok := registry.Lookup("/static/site.css"); ok || value != 0 || params != nil {
+		t.Errorf("Lookup of unmatched path = %v, %v, %v", value, params, ok)
+	}
+	if matches := registry.LookupAll("/static/site.css"); len(matches) != 0 {
+		t.Errorf("LookupAll of unmatched path = %v", matches)
+	}
+}
+
+func TestRegistryRegisterReplaces(t *testing.T) {
+	registry := NewRegistry[int]()
+	registry.Register("/api/**", 1)
+	registry.Register("/api/users", 2)
+	registry.Register("/api/**", 3)
+	if value, _, _ := registry.Lookup("/api/groups"); value != 3 {
+		t.Errorf("Lookup after re-registering = %v, want 3", value)
+	}
+	if matches := registry.LookupAll("/api/users"); len(matches) != 2 {
+		t.Errorf("LookupAll after re-registering returned %d matches, want 2", len(matches))
+	}
+}
+
+func TestRegistryLookupAllOrder(t *testing.T) {
+	patterns := []string{"/**", "/api/{version}/**", "/api/**", "/api/v1/*", "/api/v1/users", "/api/*/users", "/api/{version}/users"}
+	want := []string{"/api/v1/users", "/api/{version}/users", "/api/*/users", "/api/v1/*", "/api/**", "/api/{version}/**", "/**"}
+	// 结果的顺序与注册的顺序无关
+	for _, order := range [][]string{patterns, reversed(patterns)} {
+		registry := NewRegistry[string]()
+		for _, pattern := range order {
+			registry.Register(pattern, pattern)
+		}
+		matches := registry.LookupAll("/api/v1/users")
+		got := make([]string, 0, len(matches))
+		for _, match := range matches {
+			if match.Value != match.Pattern {
+				t.Errorf("LookupAll returned value %q for pattern %q", match.Value, match.Pattern)
+			}
+			got = append(got, match.Pattern)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("LookupAll order = %v, want %v", got, want)
+		}
+		if value, _, _ := registry.Lookup("/api/v1/users"); value != want[0] {
+			t.Errorf("Lookup = %q, want %q", value, want[0])
+		}
+	}
+}
+
+func TestRegistryLookupAllParams(t *testing.T) {
+	registry := NewRegistry[int]()
+	registry.Register("/hotels/{hotel}/**", 1)
+	registry.Register("/hotels/{hotel}/bookings/{booking}", 2)
+	matches := registry.LookupAll("/hotels/1/bookings/2")
+	want := []RegistryMatch[int]{
+		{Pattern: "/hotels/{hotel}/bookings/{booking}", Value: 2, Params: Params{{"hotel", "1"}, {"booking", "2"}}},
+		{Pattern: "/hotels/{hotel}/**", Value: 1, Params: Params{{"hotel", "1"}}},
+	}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("LookupAll = %v, want %v", matches, want)
+	}
+}
+
+func TestRegistryWithMatcher(t *testing.T) {
+	registry := NewRegistryWithMatcher[string](NewS(DestinationSeparator))
+	registry.Register("orders.**", "orders")
+	registry.Register("orders.{region}.created", "created")
+	value, params, ok := registry.Lookup("orders.eu.created")
+	if !ok || value != "created" || !reflect.DeepEqual(params, Params{{"region", "eu"}}) {
+		t.Errorf("Lookup = %q, %v, %v", value, params, ok)
+	}
+}
+
+// reversed 返回倒序排列的副本
+func reversed(values []string) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[len(values)-1-i] = value
+	}
+	return result
+}
@@ -0,0 +1,577 @@
+package antstyle
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	headerAccept      = "Accept"
+	headerContentType = "Content-Type"
+	defaultMediaType  = "application/octet-stream" // 请求没有Content-Type时视为该类型
+	allMediaTypes     = "*/*"
+)
+
+// RequestMappingInfo 请求映射的条件，类似于Spring的RequestMappingInfo
+/**
+ *Patterns为路径模式；Methods为HTTP方法，为空时匹配任意方法；
+ *Params与Headers为表达式"name"、"!name"、"name=value"或"name!=value"，全部满足时才匹配，
+ *其中参数只取自URL的查询字符串；Headers中的"Content-Type=..."与"Accept=..."分别并入Consumes与Produces，
+ *"Content-Type!=..."与"Accept!=..."以取反的媒体类型并入，不带值的"!Content-Type"与"!Accept"要求请求中没有该请求头。
+ *Consumes与Produces为媒体类型，可以用"!"取反，分别与请求的Content-Type和Accept比较。
+ *Patterns为空时匹配任意路径。Matcher为nil时使用包级别的默认PathMatcher。
+ */
+type RequestMappingInfo struct {
+	Patterns []string
+	Methods  []string
+	Params   []string
+	Headers  []string
+	Consumes []string
+	Produces []string
+	Matcher  PathMatcher
+}
+
+// Matches 判断请求是否满足所有条件
+func (info *RequestMappingInfo) Matches(request *http.Request) bool {
+	return info.GetMatchingCondition(request) != nil
+}
+
+// GetMatchingCondition 返回只包含与请求匹配的部分的条件，不匹配时返回nil
+/**
+ *与Spring相同，返回的Patterns按与请求路径的具体程度排序，Methods只保留请求的方法，
+ *Consumes与Produces只保留与请求匹配的媒体类型，Headers中的Content-Type与Accept已并入其中。返回值可用于Compare。
+ */
+func (info *RequestMappingInfo) GetMatchingCondition(request *http.Request) *RequestMappingInfo {
+	methods, ok := info.matchingMethods(request.Method)
+	if !ok {
+		return nil
+	}
+	query := request.URL.Query()
+	for _, expression := range info.Params {
+		if !matchNameValueExpression(expression, query) {
+			return nil
+		}
+	}
+	headers := url.Values(request.Header)
+	for _, expression := range info.Headers {
+		if !isMediaTypeExpression(expression) && !matchNameValueExpression(expression, headers) {
+			return nil
+		}
+	}
+	consumes, ok := matchingConsumes(info.consumes(), request.Header.Get(headerContentType))
+	if !ok {
+		return nil
+	}
+	produces, ok := matchingProduces(info.produces(), acceptedMediaTypes(request))
+	if !ok {
+		return nil
+	}
+	patterns, ok := info.matchingPatterns(request.URL.Path)
+	if !ok {
+		return nil
+	}
+	return &RequestMappingInfo{
+		Patterns: patterns,
+		Methods:  methods,
+		Params:   info.Params,
+		Headers:  info.headerExpressions(),
+		Consumes: consumes,
+		Produces: produces,
+		Matcher:  info.Matcher,
+	}
+}
+
+// Compare 在两个都与请求匹配的条件中比较谁更具体，返回负数表示info优先
+/**
+ *与Spring相同，HEAD请求先比较方法，使显式的HEAD映射优先于其他映射；之后依次比较路径模式、参数、请求头、Consumes、Produces与方法，
+ *应在GetMatchingCondition返回的条件之间比较。
+ */
+func (info *RequestMappingInfo) Compare(other *RequestMappingInfo, request *http.Request) int {
+	if strings.EqualFold(request.Method, http.MethodHead) {
+		if result := compareMethods(info.Methods, other.Methods); result != 0 {
+			return result
+		}
+	}
+	if result := comparePatterns(info.Patterns, other.Patterns, request.URL.Path); result != 0 {
+		return result
+	}
+	if result := compareExpressions(info.Params, other.Params); result != 0 {
+		return result
+	}
+	if result := compareExpressions(info.headerExpressions(), other.headerExpressions()); result != 0 {
+		return result
+	}
+	if result := compareConsumes(info.consumes(), other.consumes()); result != 0 {
+		return result
+	}
+	if result := compareProduces(info.produces(), other.produces(), acceptedMediaTypes(request)); result != 0 {
+		return result
+	}
+	return compareMethods(info.Methods, other.Methods)
+}
+
+// Combine 将类型级别的映射（info）与方法级别的映射（other）合并
+/**
+ *路径模式两两使用PathMatcher.Combine合并，方法、参数与请求头取并集，
+ *Consumes与Produces在other中指定时覆盖info中的值。
+ */
+func (info *RequestMappingInfo) Combine(other *RequestMappingInfo) *RequestMappingInfo {
+	pathMatcher := info.pathMatcher()
+	patterns := make([]string, 0)
+	switch {
+	case len(info.Patterns) > 0 && len(other.Patterns) > 0:
+		for _, pattern1 := range info.Patterns {
+			for _, pattern2 := range other.Patterns {
+				patterns = append(patterns, pathMatcher.Combine(pattern1, pattern2))
+			}
+		}
+	case len(info.Patterns) > 0:
+		patterns = append(patterns, info.Patterns...)
+	default:
+		patterns = append(patterns, other.Patterns...)
+	}
+	combined := &RequestMappingInfo{
+		Patterns: patterns,
+		Methods:  unionStrings(info.Methods, other.Methods),
+		Params:   unionStrings(info.Params, other.Params),
+		Headers:  unionStrings(info.Headers, other.Headers),
+		Consumes: info.Consumes,
+		Produces: info.Produces,
+		Matcher:  info.Matcher,
+	}
+	if len(other.Consumes) > 0 {
+		combined.Consumes = other.Consumes
+	}
+	if len(other.Produces) > 0 {
+		combined.Produces = other.Produces
+	}
+	return combined
+}
+
+// pathMatcher 返回使用的PathMatcher
+func (info *RequestMappingInfo) pathMatcher() PathMatcher {
+	if info.Matcher != nil {
+		return info.Matcher
+	}
+	return matcher
+}
+
+// matchingPatterns 返回与路径匹配的模式，按具体程度排序
+func (info *RequestMappingInfo) matchingPatterns(path string) ([]string, bool) {
+	if len(info.Patterns) == 0 {
+		return info.Patterns, true
+	}
+	pathMatcher := info.pathMatcher()
+	patterns := make([]string, 0)
+	for _, pattern := range info.Patterns {
+		if pattern == path || pathMatcher.Match(pattern, path) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		return nil, false
+	}
+	pathMatcher.GetPatternComparator(path).Sort(patterns)
+	return patterns, true
+}
+
+// matchingMethods 返回与请求方法匹配的方法，HEAD请求也匹配GET
+func (info *RequestMappingInfo) matchingMethods(method string) ([]string, bool) {
+	if len(info.Methods) == 0 {
+		return info.Methods, true
+	}
+	for _, candidate := range info.Methods {
+		if strings.EqualFold(candidate, method) {
+			return []string{candidate}, true
+		}
+	}
+	if strings.EqualFold(method, http.MethodHead) {
+		for _, candidate := range info.Methods {
+			if strings.EqualFold(candidate, http.MethodGet) {
+				return []string{candidate}, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// headerExpressions 返回不含并入Consumes与Produces的表达式的请求头表达式
+func (info *RequestMappingInfo) headerExpressions() []string {
+	expressions := make([]string, 0, len(info.Headers))
+	for _, expression := range info.Headers {
+		if !isMediaTypeExpression(expression) {
+			expressions = append(expressions, expression)
+		}
+	}
+	return expressions
+}
+
+// consumes 返回Consumes以及请求头表达式中的Content-Type
+func (info *RequestMappingInfo) consumes() []string {
+	return mediaTypeExpressions(info.Consumes, info.Headers, headerContentType)
+}
+
+// produces 返回Produces以及请求头表达式中的Accept
+func (info *RequestMappingInfo) produces() []string {
+	return mediaTypeExpressions(info.Produces, info.Headers, headerAccept)
+}
+
+// mediaTypeExpressions 合并媒体类型与请求头表达式"name=type1,type2"中的媒体类型，"name!=type"中的媒体类型取反
+func mediaTypeExpressions(mediaTypes, headers []string, name string) []string {
+	expressions := append(make([]string, 0, len(mediaTypes)), mediaTypes...)
+	for _, header := range headers {
+		expression := parseNameValueExpression(header)
+		if strings.EqualFold(expression.name, name) && expression.hasValue {
+			for _, value := range strings.Split(expression.value, ",") {
+				if expression.negated {
+					expressions = append(expressions, "!"+strings.TrimSpace(value))
+				} else {
+					expressions = append(expressions, strings.TrimSpace(value))
+				}
+			}
+		}
+	}
+	return expressions
+}
+
+// isMediaTypeExpression 是否为并入Consumes或Produces的请求头表达式，即带值的Content-Type或Accept表达式
+func isMediaTypeExpression(expression string) bool {
+	parsed := parseNameValueExpression(expression)
+	return parsed.hasValue && (strings.EqualFold(parsed.name, headerContentType) || strings.EqualFold(parsed.name, headerAccept))
+}
+
+// nameValueExpression 解析后的"name"、"!name"、"name=value"或"name!=value"表达式
+type nameValueExpression struct {
+	name     string
+	value    string
+	hasValue bool
+	negated  bool
+}
+
+// parseNameValueExpression 解析参数或请求头表达式
+func parseNameValueExpression(expression string) nameValueExpression {
+	if idx := strings.Index(expression, "!="); idx != -1 {
+		return nameValueExpression{name: expression[:idx], value: expression[idx+2:], hasValue: true, negated: true}
+	}
+	if idx := strings.Index(expression, "="); idx != -1 {
+		return nameValueExpression{name: expression[:idx], value: expression[idx+1:], hasValue: true}
+	}
+	if strings.HasPrefix(expression, "!") {
+		return nameValueExpression{name: expression[1:], negated: true}
+	}
+	return nameValueExpression{name: expression}
+}
+
+// matchNameValueExpression 判断表达式是否被满足，请求头的名称不区分大小写
+func matchNameValueExpression(expression string, values url.Values) bool {
+	parsed := parseNameValueExpression(expression)
+	actual, present := values[parsed.name]
+	if !present {
+		actual, present = values[http.CanonicalHeaderKey(parsed.name)]
+	}
+	if !parsed.hasValue {
+		return present != parsed.negated
+	}
+	matched := false
+	for _, value := range actual {
+		if value == parsed.value {
+			matched = true
+			break
+		}
+	}
+	return matched != parsed.negated
+}
+
+// compareExpressions 表达式更多的优先，个数相同时带值的表达式更多的优先
+func compareExpressions(expressions1, expressions2 []string) int {
+	if result := len(expressions2) - len(expressions1); result != 0 {
+		return result
+	}
+	return countValueExpressions(expressions2) - countValueExpressions(expressions1)
+}
+
+// countValueExpressions 返回"name=value"形式的表达式个数
+func countValueExpressions(expressions []string) int {
+	count := 0
+	for _, expression := range expressions {
+		if parsed := parseNameValueExpression(expression); parsed.hasValue && !parsed.negated {
+			count++
+		}
+	}
+	return count
+}
+
+// comparePatterns 按比较器依次比较两组已排序的模式，前面的模式都相同时与Spring一样模式较多的优先
+func comparePatterns(patterns1, patterns2 []string, path string) int {
+	comparator := NewDefaultAntPatternComparator(path)
+	for i := 0; i < len(patterns1) && i < len(patterns2); i++ {
+		if result := comparator.Compare(patterns1[i], patterns2[i]); result != 0 {
+			return result
+		}
+	}
+	return len(patterns2) - len(patterns1)
+}
+
+// compareMethods 指定了方法的优先，HEAD优先于GET
+func compareMethods(methods1, methods2 []string) int {
+	if len(methods1) != len(methods2) {
+		return len(methods2) - len(methods1)
+	}
+	if len(methods1) == 1 {
+		if strings.EqualFold(methods1[0], http.MethodHead) && strings.EqualFold(methods2[0], http.MethodGet) {
+			return -1
+		}
+		if strings.EqualFold(methods1[0], http.MethodGet) && strings.EqualFold(methods2[0], http.MethodHead) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// mediaType 解析后的媒体类型
+type mediaType struct {
+	typ     string
+	subtype string
+	params  int     // 除q以外的参数个数
+	quality float64 // Accept中的q参数，默认为1
+	negated bool    // 表达式是否以"!"开头
+}
+
+// parseMediaType 解析媒体类型表达式，无法解析时返回false
+func parseMediaType(expression string) (mediaType, bool) {
+	parsed := mediaType{quality: 1}
+	expression = strings.TrimSpace(expression)
+	if strings.HasPrefix(expression, "!") {
+		parsed.negated = true
+		expression = expression[1:]
+	}
+	if expression == "*" {
+		expression = allMediaTypes
+	}
+	full, params, err := mime.ParseMediaType(expression)
+	if err != nil {
+		return parsed, false
+	}
+	slash := strings.Index(full, "/")
+	if slash == -1 {
+		return parsed, false
+	}
+	parsed.typ, parsed.subtype = full[:slash], full[slash+1:]
+	for name, value := range params {
+		if name == "q" {
+			if quality, err := strconv.ParseFloat(value, 64); err == nil {
+				parsed.quality = quality
+			}
+			continue
+		}
+		parsed.params++
+	}
+	return parsed, true
+}
+
+// includes 判断媒体类型是否包含另一个，例如"text/*"包含"text/plain"，"application/*+json"包含"application/hal+json"
+func (m mediaType) includes(other mediaType) bool {
+	if m.typ == "*" {
+		return true
+	}
+	if m.typ != other.typ {
+		return false
+	}
+	if m.subtype == other.subtype || m.subtype == "*" {
+		return true
+	}
+	if strings.HasPrefix(m.subtype, "*+") {
+		plus := strings.LastIndex(other.subtype, "+")
+		return plus != -1 && other.subtype[plus:] == m.subtype[1:]
+	}
+	return false
+}
+
+// compatible 判断两个媒体类型是否互相兼容
+func (m mediaType) compatible(other mediaType) bool {
+	return m.includes(other) || other.includes(m)
+}
+
+// equal 判断两个媒体类型的类型与子类型是否相同
+func (m mediaType) equal(other mediaType) bool {
+	return m.typ == other.typ && m.subtype == other.subtype
+}
+
+// compareSpecificity 更具体的媒体类型优先：不含通配符的优先，其次参数更多的优先
+func compareSpecificity(m1, m2 mediaType) int {
+	if (m1.typ == "*") != (m2.typ == "*") {
+		if m1.typ == "*" {
+			return 1
+		}
+		return -1
+	}
+	if (m1.subtype == "*") != (m2.subtype == "*") {
+		if m1.subtype == "*" {
+			return 1
+		}
+		return -1
+	}
+	return m2.params - m1.params
+}
+
+// parseMediaTypes 解析一组媒体类型表达式，忽略无法解析的表达式
+func parseMediaTypes(expressions []string) []mediaType {
+	mediaTypes := make([]mediaType, 0, len(expressions))
+	for _, expression := range expressions {
+		if parsed, ok := parseMediaType(expression); ok {
+			mediaTypes = append(mediaTypes, parsed)
+		}
+	}
+	return mediaTypes
+}
+
+// acceptedMediaTypes 返回请求的Accept中的媒体类型，按质量与具体程度排序，没有Accept时为"*/*"
+func acceptedMediaTypes(request *http.Request) []mediaType {
+	expressions := make([]string, 0)
+	for _, header := range request.Header.Values(headerAccept) {
+		expressions = append(expressions, strings.Split(header, ",")...)
+	}
+	accepted := parseMediaTypes(expressions)
+	if len(accepted) == 0 {
+		accepted = parseMediaTypes([]string{allMediaTypes})
+	}
+	sort.SliceStable(accepted, func(i, j int) bool {
+		if accepted[i].quality != accepted[j].quality {
+			return accepted[i].quality > accepted[j].quality
+		}
+		return compareSpecificity(accepted[i], accepted[j]) < 0
+	})
+	return accepted
+}
+
+// matchingConsumes 返回与Content-Type匹配的表达式，条件为空时匹配任意请求
+func matchingConsumes(expressions []string, contentType string) ([]string, bool) {
+	if len(expressions) == 0 {
+		return expressions, true
+	}
+	if contentType == "" {
+		contentType = defaultMediaType
+	}
+	actual, ok := parseMediaType(contentType)
+	if !ok {
+		return nil, false
+	}
+	matching := make([]string, 0)
+	for _, expression := range expressions {
+		if parsed, ok := parseMediaType(expression); ok && parsed.includes(actual) != parsed.negated {
+			matching = append(matching, expression)
+		}
+	}
+	return matching, len(matching) > 0
+}
+
+// matchingProduces 返回与Accept兼容的表达式，条件为空时匹配任意请求
+func matchingProduces(expressions []string, accepted []mediaType) ([]string, bool) {
+	if len(expressions) == 0 {
+		return expressions, true
+	}
+	matching := make([]string, 0)
+	for _, expression := range expressions {
+		parsed, ok := parseMediaType(expression)
+		if !ok {
+			continue
+		}
+		compatible := false
+		for _, acceptedType := range accepted {
+			if parsed.compatible(acceptedType) {
+				compatible = true
+				break
+			}
+		}
+		if compatible != parsed.negated {
+			matching = append(matching, expression)
+		}
+	}
+	return matching, len(matching) > 0
+}
+
+// compareConsumes 指定了媒体类型的优先，其次与Spring相同比较双方最具体的媒体类型
+func compareConsumes(expressions1, expressions2 []string) int {
+	mediaTypes1, mediaTypes2 := parseMediaTypes(expressions1), parseMediaTypes(expressions2)
+	switch {
+	case len(mediaTypes1) == 0 && len(mediaTypes2) == 0:
+		return 0
+	case len(mediaTypes1) == 0:
+		return 1
+	case len(mediaTypes2) == 0:
+		return -1
+	}
+	for _, mediaTypes := range [][]mediaType{mediaTypes1, mediaTypes2} {
+		sort.SliceStable(mediaTypes, func(i, j int) bool {
+			return compareSpecificity(mediaTypes[i], mediaTypes[j]) < 0
+		})
+	}
+	return compareSpecificity(mediaTypes1[0], mediaTypes2[0])
+}
+
+// compareProduces 按请求接受的媒体类型依次比较，与之相同的媒体类型优先于仅被其包含的媒体类型
+func compareProduces(expressions1, expressions2 []string, accepted []mediaType) int {
+	mediaTypes1, mediaTypes2 := parseMediaTypes(expressions1), parseMediaTypes(expressions2)
+	switch {
+	case len(mediaTypes1) == 0 && len(mediaTypes2) == 0:
+		return 0
+	case len(mediaTypes1) == 0:
+		return 1
+	case len(mediaTypes2) == 0:
+		return -1
+	}
+	for _, acceptedType := range accepted {
+		equal := func(m mediaType) bool { return acceptedType.equal(m) }
+		if result := compareMatchingMediaTypes(mediaTypes1, mediaTypes2, equal); result != 0 {
+			return result
+		}
+		included := func(m mediaType) bool { return acceptedType.includes(m) }
+		if result := compareMatchingMediaTypes(mediaTypes1, mediaTypes2, included); result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+// compareMatchingMediaTypes 比较两组媒体类型中第一个满足条件的媒体类型：只有一方满足时该方优先，都满足时比较具体程度
+func compareMatchingMediaTypes(mediaTypes1, mediaTypes2 []mediaType, condition func(mediaType) bool) int {
+	index1, index2 := indexOfMediaType(mediaTypes1, condition), indexOfMediaType(mediaTypes2, condition)
+	switch {
+	case index1 == -1 && index2 == -1:
+		return 0
+	case index1 == -1:
+		return 1
+	case index2 == -1:
+		return -1
+	}
+	return compareSpecificity(mediaTypes1[index1], mediaTypes2[index2])
+}
+
+// indexOfMediaType 返回第一个满足条件且未取反的媒体类型的下标
+func indexOfMediaType(mediaTypes []mediaType, condition func(mediaType) bool) int {
+	for i, m := range mediaTypes {
+		if !m.negated && condition(m) {
+			return i
+		}
+	}
+	return -1
+}
+
+// unionStrings 按出现顺序合并两组字符串并去除重复
+func unionStrings(values1, values2 []string) []string {
+	union := make([]string, 0, len(values1)+len(values2))
+	seen := make(map[string]bool, len(values1)+len(values2))
+	for _, values := range [][]string{values1, values2} {
+		for _, value := range values {
+			if !seen[value] {
+				seen[value] = true
+				union = append(union, value)
+			}
+		}
+	}
+	return union
+}
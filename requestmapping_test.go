@@ -0,0 +1,89 @@
+package antstyle
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompareMorePatternsFirst(t *testing.T) {
+	request := httptest.NewRequest("GET", "/users/7", nil)
+	one := (&RequestMappingInfo{Patterns: []string{"/users/{id}"}}).GetMatchingCondition(request)
+	two := (&RequestMappingInfo{Patterns: []string{"/users/{id}", "/users/*"}}).GetMatchingCondition(request)
+	if one == nil || two == nil {
+		t.Fatalf("GetMatchingCondition returned nil: %v, %v", one, two)
+	}
+	if result := two.Compare(one, request); result >= 0 {
+		t.Errorf("two.Compare(one) = %d, want negative", result)
+	}
+	if result := one.Compare(two, request); result <= 0 {
+		t.Errorf("one.Compare(two) = %d, want positive", result)
+	}
+}
+
+func TestCompareExplicitHeadFirst(t *testing.T) {
+	request := httptest.NewRequest("HEAD", "/x", nil)
+	head := (&RequestMappingInfo{Patterns: []string{"/*"}, Methods: []string{"HEAD"}}).GetMatchingCondition(request)
+	get := (&RequestMappingInfo{Patterns: []string{"/x"}, Methods: []string{"GET"}}).GetMatchingCondition(request)
+	if head == nil || get == nil {
+		t.Fatalf("GetMatchingCondition returned nil: %v, %v", head, get)
+	}
+	if result := head.Compare(get, request); result >= 0 {
+		t.Errorf("head.Compare(get) = %d, want negative", result)
+	}
+	if result := get.Compare(head, request); result <= 0 {
+		t.Errorf("get.Compare(head) = %d, want positive", result)
+	}
+
+	request = httptest.NewRequest("GET", "/x", nil)
+	glob := (&RequestMappingInfo{Patterns: []string{"/*"}, Methods: []string{"GET"}}).GetMatchingCondition(request)
+	get = (&RequestMappingInfo{Patterns: []string{"/x"}}).GetMatchingCondition(request)
+	if result := get.Compare(glob, request); result >= 0 {
+		t.Errorf("for GET the more specific pattern should win, got %d", result)
+	}
+}
+
+func TestCompareConsumesBySpecificity(t *testing.T) {
+	request := httptest.NewRequest("POST", "/x", nil)
+	request.Header.Set("Content-Type", "text/plain")
+	wildcardFirst := (&RequestMappingInfo{Consumes: []string{"text/*", "text/plain"}}).GetMatchingCondition(request)
+	wildcard := (&RequestMappingInfo{Consumes: []string{"text/*"}}).GetMatchingCondition(request)
+	if wildcardFirst == nil || wildcard == nil {
+		t.Fatalf("GetMatchingCondition returned nil: %v, %v", wildcardFirst, wildcard)
+	}
+	if result := wildcardFirst.Compare(wildcard, request); result >= 0 {
+		t.Errorf("Compare = %d, want negative because text/plain is more specific than text/*", result)
+	}
+}
+
+func TestNegatedMediaTypeHeaders(t *testing.T) {
+	cases := []struct {
+		name        string
+		headers     []string
+		contentType string
+		accept      string
+		want        bool
+	}{
+		{"NoContentTypeAbsent", []string{"!Content-Type"}, "", "", true},
+		{"NoContentTypePresent", []string{"!Content-Type"}, "application/json", "", false},
+		{"NoAcceptAbsent", []string{"!Accept"}, "", "", true},
+		{"NoAcceptPresent", []string{"!Accept"}, "", "text/html", false},
+		{"ContentTypeNotJSON", []string{"Content-Type!=application/json"}, "text/plain", "", true},
+		{"ContentTypeIsJSON", []string{"Content-Type!=application/json"}, "application/json", "", false},
+		{"AcceptNotHTML", []string{"Accept!=text/html"}, "", "application/json", true},
+		{"AcceptOnlyHTML", []string{"Accept!=text/html"}, "", "text/html", false},
+		{"ContentTypeIs", []string{"Content-Type=application/json"}, "application/json", "", true},
+	}
+	for _, c := range cases {
+		request := httptest.NewRequest("POST", "/x", nil)
+		if c.contentType != "" {
+			request.Header.Set("Content-Type", c.contentType)
+		}
+		if c.accept != "" {
+			request.Header.Set("Accept", c.accept)
+		}
+		info := &RequestMappingInfo{Headers: c.headers}
+		if got := info.Matches(request); got != c.want {
+			t.Errorf("%s: Matches = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package antstyle
+
+import (
+	"container/list"
+	"sync"
+)
+
+// ResultCacheStats 结果缓存的统计信息
+type ResultCacheStats struct {
+	Hits      uint64 // 命中次数
+	Misses    uint64 // 未命中次数
+	Evictions uint64 // 因超出容量被淘汰的条目数
+	Size      int    // 当前的条目数
+	Capacity  int    // 最大条目数
+}
+
+// resultKey 结果缓存的键
+type resultKey struct {
+	pattern   string
+	path      string
+	fullMatch bool
+}
+
+// resultEntry 结果缓存的条目
+type resultEntry struct {
+	key      resultKey
+	matched  bool
+	err      error
+	params   Params // 匹配时提取的变量
+	captured bool   // 是否已经提取过变量，没有变量的模式提取后params为空但captured为true
+}
+
+// resultCache 按最近最少使用淘汰的匹配结果缓存（线程安全）
+/**
+ *读取也会调整条目的顺序，因此使用互斥锁而不是读写锁。
+ */
+type resultCache struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[resultKey]*list.Element
+	order    *list.List // 最近使用的条目在前
+	stats    ResultCacheStats
+}
+
+// newResultCache 构造函数
+func newResultCache(capacity int) *resultCache {
+	return &resultCache{
+		capacity: capacity,
+		entries:  make(map[resultKey]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// load 查找缓存的结果，需要变量而条目中没有时视为未命中
+func (cache *resultCache) load(key resultKey, params *Params) (bool, error, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	element, ok := cache.entries[key]
+	if ok {
+		entry := element.Value.(*resultEntry)
+		if params == nil || !entry.matched || entry.err != nil || entry.captured {
+			cache.stats.Hits++
+			cache.order.MoveToFront(element)
+			if params != nil {
+				*params = append(*params, entry.params...)
+			}
+			return entry.matched, entry.err, true
+		}
+	}
+	cache.stats.Misses++
+	return false, nil, false
+}
+
+// store 保存匹配结果，captured表示params是否为提取变量的结果，超出容量时淘汰最久未使用的条目
+func (cache *resultCache) store(key resultKey, matched bool, err error, params Params, captured bool) {
+	entry := &resultEntry{key: key, matched: matched, err: err, captured: captured}
+	if matched && captured {
+		entry.params = append(make(Params, 0, len(params)), params...)
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if element, ok := cache.entries[key]; ok {
+		// 不用没有变量的结果覆盖已经提取过变量的条目
+		if captured || !element.Value.(*resultEntry).captured {
+			element.Value = entry
+		}
+		cache.order.MoveToFront(element)
+		return
+	}
+	cache.entries[key] = cache.order.PushFront(entry)
+	for cache.order.Len() > cache.capacity {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*resultEntry).key)
+		cache.stats.Evictions++
+	}
+}
+
+// clear 清除所有条目，保留统计信息
+func (cache *resultCache) clear() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.entries = make(map[resultKey]*list.Element, cache.capacity)
+	cache.order.Init()
+}
+
+// getStats 返回统计信息的快照
+func (cache *resultCache) getStats() ResultCacheStats {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	stats := cache.stats
+	stats.Size = cache.order.Len()
+	stats.Capacity = cache.capacity
+	return stats
+}
+
+// SetResultCacheSize 开启或关闭匹配结果缓存
+/**
+ *开启后Match、MatchStart、TryMatch与ExtractUriTemplateVariables的结果按(pattern, path)缓存，
+ *重复的检查只需一次查找。缓存的条目数不超过size，超出时淘汰最久未使用的条目。
+ *size小于或等于0时关闭缓存。修改任何影响匹配结果的设置都会清空缓存。
+ *@param size 最大条目数
+ */
+func (ant *AntPathMatcher) SetResultCacheSize(size int) {
+	ant.resultCacheMutex.Lock()
+	defer ant.resultCacheMutex.Unlock()
+	if size <= 0 {
+		ant.resultCache = nil
+		return
+	}
+	ant.resultCache = newResultCache(size)
+}
+
+// GetResultCacheStats 返回结果缓存的统计信息，未开启缓存时返回零值
+func (ant *AntPathMatcher) GetResultCacheStats() ResultCacheStats {
+	if cache := ant.getResultCache(); cache != nil {
+		return cache.getStats()
+	}
+	return ResultCacheStats{}
+}
+
+// getResultCache 返回当前的结果缓存，未开启时为nil
+func (ant *AntPathMatcher) getResultCache() *resultCache {
+	ant.resultCacheMutex.RLock()
+	defer ant.resultCacheMutex.RUnlock()
+	return ant.resultCache
+}
+
+// clearResultCache 在匹配设置改变后清空结果缓存
+func (ant *AntPathMatcher) clearResultCache() {
+	if cache := ant.getResultCache(); cache != nil {
+		cache.clear()
+	}
+}
+
+// cachedMatch 先查找结果缓存，未命中时调用tryMatch并保存结果
+func (ant *AntPathMatcher) cachedMatch(pattern, path string, fullMatch bool, params *Params) (bool, error) {
+	cache := ant.getResultCache()
+	if cache == nil {
+		return ant.tryMatch(pattern, path, fullMatch, params)
+	}
+	key := resultKey{pattern: pattern, path: path, fullMatch: fullMatch}
+	if matched, err, ok := cache.load(key, params); ok {
+		return matched, err
+	}
+	var mark int
+	if params != nil {
+		mark = len(*params)
+	}
+	matched, err := ant.tryMatch(pattern, path, fullMatch, params)
+	if params == nil {
+		cache.store(key, matched, err, nil, false)
+	} else {
+		cache.store(key, matched, err, (*params)[mark:], true)
+	}
+	return matched, err
+}
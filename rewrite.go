@@ -0,0 +1,331 @@
+package antstyle
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const positionalPrefix = "$" // 模板中按位置引用"*"与"**"捕获的内容，例如"$1"
+
+// templatePart 重写模板中的一段
+type templatePart struct {
+	literal  string
+	name     string // "{name}"引用的变量名
+	position int    // "$N"引用的位置，从1开始，0表示literal或name
+}
+
+// Rewrite 路径与fromPattern匹配时，用其中的变量展开toTemplate
+/**
+ *模板中的"{name}"引用fromPattern中的URI模板变量，取值与ExtractUriTemplateVariables相同，变量名重复时后出现的值优先；
+ *"$N"按在模式中出现的顺序引用第N个"*"或"**"捕获的内容，"**"捕获的多段以分隔符连接，不含首尾的分隔符；"$$"表示字面量"$"。
+ *是否匹配与Match完全相同，例如Rewrite("/legacy/*\/**", "/v2/$1/{x}/$2", ...)。模板引用了不存在的变量或位置时，无论是否匹配都返回错误。
+ *@param fromPattern 要匹配的模式
+ *@param toTemplate 重写模板
+ *@param path 要重写的路径
+ *@return string 重写后的路径
+ *@return bool 是否匹配
+ *@return error 模板无效或超出Limits时的错误
+ */
+func (ant *AntPathMatcher) Rewrite(fromPattern, toTemplate, path string) (string, bool, error) {
+	positional, wildcards := ant.positionalSegments(fromPattern)
+	parts, err := ant.parseTemplate(fromPattern, toTemplate, wildcards)
+	if err != nil {
+		return "", false, err
+	}
+	params := make(Params, 0)
+	matched, err := ant.tryMatch(fromPattern, path, true, &params)
+	if err != nil || !matched {
+		return "", false, err
+	}
+	variables := params.ToMap()
+	var captures map[int]string
+	for _, part := range parts {
+		if part.position > 0 {
+			captures = ant.captureWildcards(fromPattern, positional, path)
+			break
+		}
+	}
+
+	var builder strings.Builder
+	for _, part := range parts {
+		switch {
+		case part.position > 0:
+			builder.WriteString(captures[part.position])
+		case part.name != "":
+			builder.WriteString(variables[part.name])
+		default:
+			builder.WriteString(part.literal)
+		}
+	}
+	return builder.String(), true, nil
+}
+
+// positionalSegments 返回模式的各段，其中不带名称的"*"替换为"{$N}"，以及模式中"*"与"**"的总数
+/**
+ *替换后的段只用于在已匹配的路径段中截取"*"捕获的内容，不参与判断整条路径是否匹配。
+ */
+func (ant *AntPathMatcher) positionalSegments(pattern string) ([]string, int) {
+	wildcards := 0
+	segments := make([]string, 0)
+	for _, token := range ant.tokenizePath(pattern) {
+		segment := *token
+		if segment == "**" {
+			wildcards++
+			segments = append(segments, segment)
+			continue
+		}
+		var builder strings.Builder
+		end := 0
+		for _, matched := range GlobPattern.FindAllStringIndex(segment, MaxFindCount) {
+			builder.WriteString(segment[end:matched[0]])
+			if segment[matched[0]:matched[1]] == "*" {
+				wildcards++
+				builder.WriteString("{" + positionalPrefix + strconv.Itoa(wildcards) + "}")
+			} else {
+				builder.WriteString(segment[matched[0]:matched[1]])
+			}
+			end = matched[1]
+		}
+		builder.WriteString(segment[end:])
+		segments = append(segments, builder.String())
+	}
+	return segments, wildcards
+}
+
+// parseTemplate 解析重写模板，检查引用的变量与位置在模式中是否存在
+func (ant *AntPathMatcher) parseTemplate(pattern, template string, wildcards int) ([]templatePart, error) {
+	declared := make(map[string]bool)
+	for _, variable := range ant.Inspect(pattern).Variables {
+		declared[variable.Name] = true
+	}
+	parts := make([]templatePart, 0)
+	var literal strings.Builder
+	for i := 0; i < len(template); {
+		switch {
+		case template[i] == '{':
+			end := strings.Index(template[i:], "}")
+			if end == -1 {
+				return nil, fmt.Errorf("antstyle: unclosed variable in rewrite template %q", template)
+			}
+			name := template[i+1 : i+end]
+			if !declared[name] {
+				return nil, fmt.Errorf("antstyle: rewrite template %q uses variable %q not declared in %q", template, name, pattern)
+			}
+			parts = append(parts, templatePart{literal: literal.String()}, templatePart{name: name})
+			literal.Reset()
+			i += end + 1
+		case strings.HasPrefix(template[i:], positionalPrefix+positionalPrefix):
+			literal.WriteString(positionalPrefix)
+			i += 2 * len(positionalPrefix)
+		case strings.HasPrefix(template[i:], positionalPrefix) && i+1 < len(template) && isDigit(template[i+1]):
+			end := i + 1
+			for end < len(template) && isDigit(template[end]) {
+				end++
+			}
+			position, _ := strconv.Atoi(template[i+1 : end])
+			if position < 1 || position > wildcards {
+				return nil, fmt.Errorf("antstyle: rewrite template %q references $%d but %q has %d wildcards", template, position, pattern, wildcards)
+			}
+			parts = append(parts, templatePart{literal: literal.String()}, templatePart{position: position})
+			literal.Reset()
+			i = end
+		default:
+			literal.WriteByte(template[i])
+			i++
+		}
+	}
+	return append(parts, templatePart{literal: literal.String()}), nil
+}
+
+// captureWildcards 返回已匹配的路径中每个"*"与"**"捕获的内容，键为它们在模式中的位置
+func (ant *AntPathMatcher) captureWildcards(pattern string, positional []string, path string) map[int]string {
+	segments := ant.compilePattern(pattern).segments
+	pathDirs := ant.splitPath(path, nil)
+	spans := ant.alignSegments(segments, pathDirs)
+	captures := make(map[int]string)
+	position := 0
+	for i := range segments {
+		if segments[i].isDoubleWildcard() {
+			position++
+			captures[position] = strings.Join(pathDirs[spans[i][0]:spans[i][1]], ant.pathSeparator)
+			continue
+		}
+		if !strings.Contains(positional[i], "{"+positionalPrefix) {
+			continue
+		}
+		params := make(Params, 0)
+		if spans[i][0] < spans[i][1] {
+			ant.getStringMatcher(positional[i]).matchParams(pathDirs[spans[i][0]], &params)
+		}
+		// 路径以分隔符结尾时最后的"*"可以不匹配任何路径段，此时捕获的内容为空
+		for j := 0; j < strings.Count(positional[i], "{"+positionalPrefix); j++ {
+			position++
+			captures[position] = ""
+		}
+		for _, param := range params {
+			if strings.HasPrefix(param.Key, positionalPrefix) {
+				index, _ := strconv.Atoi(param.Key[len(positionalPrefix):])
+				captures[index] = param.Value
+			}
+		}
+	}
+	return captures
+}
+
+// alignSegments 将已匹配的路径段分配给模式段，返回每个模式段匹配的路径段范围[start, end)
+/**
+ *与matchSegments的顺序相同：第一个"**"之前的段从前向后、最后一个"**"之后的段从后向前一一对应，
+ *"**"之间的每组段取最左侧的匹配位置，每个"**"匹配相邻两组段之间剩余的路径段。
+ */
+func (ant *AntPathMatcher) alignSegments(segments []compiledSegment, pathDirs []string) [][2]int {
+	spans := make([][2]int, len(segments))
+	pattIdxStart, pattIdxEnd := 0, len(segments)-1
+	pathIdxStart, pathIdxEnd := 0, len(pathDirs)-1
+	for pattIdxStart <= pattIdxEnd && !segments[pattIdxStart].isDoubleWildcard() {
+		if pathIdxStart <= pathIdxEnd {
+			spans[pattIdxStart] = [2]int{pathIdxStart, pathIdxStart + 1}
+			pathIdxStart++
+		} else {
+			spans[pattIdxStart] = [2]int{pathIdxStart, pathIdxStart}
+		}
+		pattIdxStart++
+	}
+	for pattIdxStart <= pattIdxEnd && !segments[pattIdxEnd].isDoubleWildcard() {
+		spans[pattIdxEnd] = [2]int{pathIdxEnd, pathIdxEnd + 1}
+		pattIdxEnd--
+		pathIdxEnd--
+	}
+	for pattIdxStart < pattIdxEnd {
+		patIdxTmp := pattIdxStart + 1
+		for !segments[patIdxTmp].isDoubleWildcard() {
+			patIdxTmp++
+		}
+		patLength := patIdxTmp - pattIdxStart - 1
+		foundIdx := pathIdxStart
+	strLoop:
+		for ; foundIdx+patLength <= pathIdxEnd+1; foundIdx++ {
+			for j := 0; j < patLength; j++ {
+				if !segments[pattIdxStart+j+1].match(pathDirs[foundIdx+j], ant.caseSensitive, nil) {
+					continue strLoop
+				}
+			}
+			break
+		}
+		for j := 0; j < patLength; j++ {
+			spans[pattIdxStart+j+1] = [2]int{foundIdx + j, foundIdx + j + 1}
+		}
+		pattIdxStart = patIdxTmp
+		pathIdxStart = foundIdx + patLength
+	}
+
+	end := 0
+	for i := range segments {
+		if segments[i].isDoubleWildcard() {
+			next := len(pathDirs)
+			for j := i + 1; j < len(segments); j++ {
+				if !segments[j].isDoubleWildcard() {
+					next = spans[j][0]
+					break
+				}
+			}
+			spans[i] = [2]int{end, next}
+		}
+		end = spans[i][1]
+	}
+	return spans
+}
+
+// isDigit 是否为ASCII数字
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// RewriteStrategy Rewriter选择规则的方式
+type RewriteStrategy int
+
+const (
+	FirstMatch RewriteStrategy = iota // 按添加顺序使用第一条匹配的规则
+	BestMatch                         // 按以字典序作为最终比较的AntPatternComparator使用最具体的规则
+)
+
+// RewriteRule 重写规则
+type RewriteRule struct {
+	From string // 要匹配的模式
+	To   string // 重写模板
+}
+
+// Rewriter 按顺序保存重写规则的重写器（线程安全）
+/**
+ *可用于迁移存储键或为旧的URL生成重定向地址，例如
+ *Add("/blog/{year}/{slug}.html", "/posts/{slug}")与Add("/static/**", "/assets/$1")。
+ */
+type Rewriter struct {
+	matcher  *AntPathMatcher
+	strategy RewriteStrategy
+	mutex    sync.RWMutex
+	rules    []RewriteRule
+}
+
+// NewRewriter 使用默认的AntPathMatcher创建重写器
+func NewRewriter(strategy RewriteStrategy) *Rewriter {
+	return NewRewriterWithMatcher(New(), strategy)
+}
+
+// NewRewriterWithMatcher 使用给定的AntPathMatcher创建重写器
+func NewRewriterWithMatcher(matcher *AntPathMatcher, strategy RewriteStrategy) *Rewriter {
+	return &Rewriter{matcher: matcher, strategy: strategy}
+}
+
+// Add 添加规则，模板引用了模式中不存在的变量或位置时返回错误
+func (rewriter *Rewriter) Add(from, to string) error {
+	// 模板在匹配之前检查，因此用空路径即可验证规则
+	if _, _, err := rewriter.matcher.Rewrite(from, to, ""); err != nil {
+		return err
+	}
+	rewriter.mutex.Lock()
+	defer rewriter.mutex.Unlock()
+	rewriter.rules = append(rewriter.rules, RewriteRule{From: from, To: to})
+	return nil
+}
+
+// Rules 返回按添加顺序排列的所有规则
+func (rewriter *Rewriter) Rules() []RewriteRule {
+	rewriter.mutex.RLock()
+	defer rewriter.mutex.RUnlock()
+	return append([]RewriteRule(nil), rewriter.rules...)
+}
+
+// Rewrite 按策略选择与路径匹配的规则并重写路径
+/**
+ *规则的选择与重写使用同一个AntPathMatcher，选中的规则未能重写时依次尝试下一条候选规则。
+ *@param path 要重写的路径
+ *@return string 重写后的路径
+ *@return bool 是否有规则匹配
+ *@return error 超出Limits时的错误
+ */
+func (rewriter *Rewriter) Rewrite(path string) (string, bool, error) {
+	rewriter.mutex.RLock()
+	defer rewriter.mutex.RUnlock()
+	candidates := make([]RewriteRule, 0)
+	for _, rule := range rewriter.rules {
+		if rewriter.matcher.Match(rule.From, path) {
+			candidates = append(candidates, rule)
+		}
+	}
+	if rewriter.strategy == BestMatch {
+		comparator := NewTotalOrderAntPatternComparator(path)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return comparator.Compare(candidates[i].From, candidates[j].From) < 0
+		})
+	}
+	for _, rule := range candidates {
+		rewritten, matched, err := rewriter.matcher.Rewrite(rule.From, rule.To, path)
+		if err != nil || matched {
+			return rewritten, matched, err
+		}
+	}
+	return "", false, nil
+}
@@ -0,0 +1,71 @@
+package antstyle
+
+import "testing"
+
+func TestRewrite(t *testing.T) {
+	cases := []struct {
+		from    string
+		to      string
+		path    string
+		want    string
+		matched bool
+	}{
+		{"/legacy/*/**", "/v2/$1/$2", "/legacy/a/b/c", "/v2/a/b/c", true},
+		{"/legacy/*/**", "/v2/$1/$2", "/legacy/a", "/v2/a/", true},
+		{"/blog/{year}/{slug}.html", "/posts/{slug}?y={year}", "/blog/2020/hi.html", "/posts/hi?y=2020", true},
+		{"/files/*.{ext}", "/f/$1/{ext}", "/files/doc.pdf", "/f/doc/pdf", true},
+		{"/a/**/x/**/y", "$1|$2", "/a/1/2/x/3/y", "1/2|3", true},
+		{"/a/**/{n}/*.txt", "$1 {n} $2 $$", "/a/b/c/d/e.txt", "b/c d e $", true},
+		{"/a/*", "/b/$1", "/a/", "/b/", true},
+		{"/a/*", "/b/$1", "/c/d", "", false},
+		{"/{id}/x/{id}", "/{id}", "/1/x/2", "/2", true},
+	}
+	ant := New()
+	for _, c := range cases {
+		got, matched, err := ant.Rewrite(c.from, c.to, c.path)
+		if err != nil || matched != c.matched || got != c.want {
+			t.Errorf("Rewrite(%q, %q, %q) = %q, %v, %v, want %q, %v", c.from, c.to, c.path, got, matched, err, c.want, c.matched)
+		}
+		if matched != ant.Match(c.from, c.path) {
+			t.Errorf("Rewrite(%q, %q, %q) matched = %v, Match disagrees", c.from, c.to, c.path, matched)
+		}
+	}
+}
+
+func TestRewriteInvalidTemplate(t *testing.T) {
+	cases := [][2]string{{"/a/*", "$2"}, {"/a/*", "$0"}, {"/a/{x}", "{y}"}, {"/a", "{x"}}
+	for _, c := range cases {
+		if _, _, err := New().Rewrite(c[0], c[1], "/zzz"); err == nil {
+			t.Errorf("Rewrite(%q, %q) returned no error", c[0], c[1])
+		}
+	}
+}
+
+func TestRewriterStrategies(t *testing.T) {
+	first := NewRewriter(FirstMatch)
+	best := NewRewriter(BestMatch)
+	for _, rewriter := range []*Rewriter{first, best} {
+		if err := rewriter.Add("/static/**", "/assets/$1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := rewriter.Add("/static/img/*.png", "/images/$1.webp"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _, _ := first.Rewrite("/static/img/a.png"); got != "/assets/img/a.png" {
+		t.Errorf("FirstMatch rewrote to %q", got)
+	}
+	if got, _, _ := best.Rewrite("/static/img/a.png"); got != "/images/a.webp" {
+		t.Errorf("BestMatch rewrote to %q", got)
+	}
+	if _, matched, _ := best.Rewrite("/other"); matched {
+		t.Error("BestMatch matched /other")
+	}
+
+	rewriter := NewRewriter(FirstMatch)
+	rewriter.Add("/a/*", "/one/$1")
+	rewriter.Add("/a/**", "/many/$1")
+	if got, matched, err := rewriter.Rewrite("/a/"); err != nil || !matched || got != "/one/" {
+		t.Errorf("Rewrite(\"/a/\") = %q, %v, %v", got, matched, err)
+	}
+}
@@ -0,0 +1,347 @@
+package antstyle
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Middleware 包装http.Handler的中间件
+type Middleware func(http.Handler) http.Handler
+
+// Route 注册在RouteGroup中的路由
+type Route struct {
+	Pattern string // 与所有上级分组的前缀合并后的完整模式
+	Handler http.Handler
+	group   *RouteGroup
+}
+
+// Tags 返回路由从根分组到所在分组继承的所有标签
+func (route *Route) Tags() []string {
+	return route.group.Tags()
+}
+
+// Middleware 返回路由从根分组到所在分组继承的所有中间件，外层分组的中间件在前
+func (route *Route) Middleware() []Middleware {
+	return route.group.Middleware()
+}
+
+// routeTree 同一棵分组树共享的路由与挂载点
+type routeTree struct {
+	mutex   sync.RWMutex
+	matcher PathMatcher
+	routes  []*Route
+	mounts  []*mount
+}
+
+// mount 挂载在某个前缀下的另一棵分组树
+type mount struct {
+	pattern      string // 以"/**"结尾的挂载模式
+	restTemplate string // 引用末尾的"**"捕获的内容的重写模板，例如"$2"
+	group        *RouteGroup
+	sub          *RouteGroup
+}
+
+// pathRewriter 支持Rewrite的PathMatcher，由AntPathMatcher实现
+type pathRewriter interface {
+	Rewrite(fromPattern, toTemplate, path string) (string, bool, error)
+}
+
+// RouteGroup 共享模式前缀、标签与中间件的一组路由
+/**
+ *子分组与路由的模式都通过PathMatcher.Combine与上级的前缀合并，与Spring合并类型级别与方法级别映射的方式相同，
+ *例如"/api/**"与"/users/{id}"合并为"/api/**\/users/{id}"，"/hotels/*"与"booking"合并为"/hotels/booking"。
+ *标签与中间件在读取时从根分组逐级继承，因此之后添加到上级分组的中间件同样作用于已注册的路由。
+ *挂载的分组树中的路由在Lookup的结果中同样继承挂载点所在分组的标签与中间件。
+ */
+type RouteGroup struct {
+	prefix     string
+	parent     *RouteGroup
+	tree       *routeTree
+	tags       []string
+	middleware []Middleware
+}
+
+// NewRouteGroup 使用包级别的默认PathMatcher创建根分组
+func NewRouteGroup(prefix string) *RouteGroup {
+	return NewRouteGroupWithMatcher(matcher, prefix)
+}
+
+// NewRouteGroupWithMatcher 使用给定的PathMatcher创建根分组
+func NewRouteGroupWithMatcher(matcher PathMatcher, prefix string) *RouteGroup {
+	return &RouteGroup{prefix: prefix, tree: &routeTree{matcher: matcher}}
+}
+
+// Group 创建前缀为当前前缀与prefix合并结果的子分组
+func (group *RouteGroup) Group(prefix string) *RouteGroup {
+	return &RouteGroup{prefix: group.tree.matcher.Combine(group.prefix, prefix), parent: group, tree: group.tree}
+}
+
+// GetPrefix 返回与所有上级分组合并后的前缀
+func (group *RouteGroup) GetPrefix() string {
+	return group.prefix
+}
+
+// Tag 为分组添加标签，返回分组本身
+func (group *RouteGroup) Tag(tags ...string) *RouteGroup {
+	group.tree.mutex.Lock()
+	defer group.tree.mutex.Unlock()
+	group.tags = append(group.tags, tags...)
+	return group
+}
+
+// Use 为分组添加中间件，返回分组本身
+func (group *RouteGroup) Use(middleware ...Middleware) *RouteGroup {
+	group.tree.mutex.Lock()
+	defer group.tree.mutex.Unlock()
+	group.middleware = append(group.middleware, middleware...)
+	return group
+}
+
+// Tags 返回从根分组到当前分组的所有标签
+func (group *RouteGroup) Tags() []string {
+	group.tree.mutex.RLock()
+	defer group.tree.mutex.RUnlock()
+	tags := make([]string, 0)
+	for _, g := range group.ancestors() {
+		tags = append(tags, g.tags...)
+	}
+	return tags
+}
+
+// Middleware 返回从根分组到当前分组的所有中间件
+func (group *RouteGroup) Middleware() []Middleware {
+	group.tree.mutex.RLock()
+	defer group.tree.mutex.RUnlock()
+	return group.middlewareChain()
+}
+
+// middlewareChain 返回从根分组到当前分组的所有中间件，调用方需持有读锁
+func (group *RouteGroup) middlewareChain() []Middleware {
+	middleware := make([]Middleware, 0)
+	for _, g := range group.ancestors() {
+		middleware = append(middleware, g.middleware...)
+	}
+	return middleware
+}
+
+// ancestors 返回从根分组到当前分组的路径
+func (group *RouteGroup) ancestors() []*RouteGroup {
+	groups := make([]*RouteGroup, 0)
+	for g := group; g != nil; g = g.parent {
+		groups = append(groups, g)
+	}
+	for i, j := 0, len(groups)-1; i < j; i, j = i+1, j-1 {
+		groups[i], groups[j] = groups[j], groups[i]
+	}
+	return groups
+}
+
+// contains 当前分组是否为g本身或其上级
+func (group *RouteGroup) contains(g *RouteGroup) bool {
+	for ; g != nil; g = g.parent {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle 注册路由，模式与分组的前缀合并
+func (group *RouteGroup) Handle(pattern string, handler http.Handler) *Route {
+	route := &Route{Pattern: group.tree.matcher.Combine(group.prefix, pattern), Handler: handler, group: group}
+	group.tree.mutex.Lock()
+	defer group.tree.mutex.Unlock()
+	group.tree.routes = append(group.tree.routes, route)
+	return route
+}
+
+// HandleFunc 注册处理函数
+func (group *RouteGroup) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) *Route {
+	return group.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// Mount 将另一棵分组树挂载到prefix下
+/**
+ *prefix与当前前缀合并后在末尾追加"/**"（已以"/**"结尾时不再追加），请求路径中由末尾的"**"匹配的部分以"/"开头交给sub处理。
+ *例如挂载在"/admin"下时，"/admin/users/1"在sub中为"/users/1"；挂载在"/api/*"下时，"/api/v1/users"在sub中为"/users"。
+ *PathMatcher不支持Rewrite时改用ExtractPathWithinPattern去除前缀。
+ *挂载模式中的变量（例如"/tenants/{tenant}"）会加入到请求的变量中。
+ */
+func (group *RouteGroup) Mount(prefix string, sub *RouteGroup) {
+	pattern := group.tree.matcher.Combine(group.prefix, prefix)
+	if !strings.HasSuffix(pattern, DefaultPathSeparator+"**") {
+		// 不使用Combine，否则"/api/*"会被合并为"/api/**"
+		pattern = strings.TrimSuffix(pattern, DefaultPathSeparator) + DefaultPathSeparator + "**"
+	}
+	// 末尾的"**"是挂载模式中的最后一个通配符
+	wildcards := 0
+	for _, segment := range strings.Split(pattern, DefaultPathSeparator) {
+		if segment == "**" {
+			wildcards++
+			continue
+		}
+		for _, token := range parseSegment(segment).tokens {
+			if token.kind == starToken && token.name == "" {
+				wildcards++
+			}
+		}
+	}
+	m := &mount{pattern: pattern, restTemplate: "$" + strconv.Itoa(wildcards), group: group, sub: sub}
+	group.tree.mutex.Lock()
+	defer group.tree.mutex.Unlock()
+	group.tree.mounts = append(group.tree.mounts, m)
+}
+
+// Routes 返回当前分组及其子分组中注册的路由，按注册顺序排列，不含挂载的分组树
+func (group *RouteGroup) Routes() []*Route {
+	group.tree.mutex.RLock()
+	defer group.tree.mutex.RUnlock()
+	routes := make([]*Route, 0)
+	for _, route := range group.tree.routes {
+		if group.contains(route.group) {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// Patterns 返回当前分组中所有路由的完整模式，包括挂载的分组树中的路由
+func (group *RouteGroup) Patterns() []string {
+	patterns := make([]string, 0)
+	for _, route := range group.Routes() {
+		patterns = append(patterns, route.Pattern)
+	}
+	group.tree.mutex.RLock()
+	mounts := append([]*mount(nil), group.tree.mounts...)
+	group.tree.mutex.RUnlock()
+	for _, m := range mounts {
+		if !group.contains(m.group) {
+			continue
+		}
+		for _, pattern := range m.sub.Patterns() {
+			patterns = append(patterns, m.mountedPattern(pattern))
+		}
+	}
+	return patterns
+}
+
+// RouteMatch Lookup的结果
+type RouteMatch struct {
+	Route      *Route
+	Pattern    string       // 路由的完整模式，挂载的分组树中的路由为与挂载模式拼接后的模式，与Patterns中的一致
+	Params     Params       // 挂载模式与路由模式中的变量
+	Path       string       // 交给路由处理的路径，挂载的分组树中为去除前缀后的路径
+	Tags       []string     // 从最外层的分组到路由所在分组的所有标签
+	Middleware []Middleware // 从最外层的分组到路由所在分组的所有中间件
+}
+
+// mountedPattern 挂载的分组树中的模式在挂载点下的完整模式，例如"/admin/**"下的"/users"为"/admin/users"
+func (m *mount) mountedPattern(pattern string) string {
+	return strings.TrimSuffix(m.pattern, "**") + strings.TrimPrefix(pattern, DefaultPathSeparator)
+}
+
+// pathWithin 返回路径中由挂载模式末尾的"**"匹配的部分，以"/"开头
+func (m *mount) pathWithin(matcher PathMatcher, path string) string {
+	if rewriter, ok := matcher.(pathRewriter); ok {
+		if rest, matched, err := rewriter.Rewrite(m.pattern, m.restTemplate, path); err == nil && matched {
+			return DefaultPathSeparator + rest
+		}
+	}
+	return DefaultPathSeparator + matcher.ExtractPathWithinPattern(m.pattern, path)
+}
+
+// routeCandidate Lookup中与路径匹配的候选路由
+type routeCandidate struct {
+	pattern string      // 完整模式
+	route   *Route      // 直接注册的路由，挂载的分组树中的路由为nil
+	mount   *mount      // 路由所在的挂载点
+	match   *RouteMatch // 在挂载的分组树中的查找结果
+}
+
+// Lookup 查找与路径匹配的最具体的路由
+/**
+ *直接注册的路由与挂载的分组树中查找到的路由一起按完整模式用AntPatternComparator排序，选出最具体的一个，
+ *完整模式相同时直接注册的路由优先，挂载的分组树之间按挂载的顺序。
+ */
+func (group *RouteGroup) Lookup(path string) (*RouteMatch, bool) {
+	group.tree.mutex.RLock()
+	matcher := group.tree.matcher
+	candidates := make([]*routeCandidate, 0)
+	for _, route := range group.tree.routes {
+		if group.contains(route.group) && matcher.Match(route.Pattern, path) {
+			candidates = append(candidates, &routeCandidate{pattern: route.Pattern, route: route})
+		}
+	}
+	mounts := make([]*mount, 0)
+	for _, m := range group.tree.mounts {
+		if group.contains(m.group) && matcher.Match(m.pattern, path) {
+			mounts = append(mounts, m)
+		}
+	}
+	group.tree.mutex.RUnlock()
+
+	for _, m := range mounts {
+		if match, ok := m.sub.Lookup(m.pathWithin(matcher, path)); ok {
+			candidates = append(candidates, &routeCandidate{pattern: m.mountedPattern(match.Pattern), mount: m, match: match})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, false
+	}
+	comparator := NewTotalOrderAntPatternComparator(path)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return comparator.Compare(candidates[i].pattern, candidates[j].pattern) < 0
+	})
+	best := candidates[0]
+
+	if best.route != nil {
+		match := &RouteMatch{Route: best.route, Pattern: best.pattern, Params: make(Params, 0), Path: path,
+			Tags: best.route.group.Tags(), Middleware: best.route.group.Middleware()}
+		extractParams(matcher, best.pattern, path, &match.Params)
+		return match, true
+	}
+	match := best.match
+	params := make(Params, 0)
+	extractParams(matcher, best.mount.pattern, path, &params)
+	match.Pattern = best.pattern
+	match.Params = append(params, match.Params...)
+	match.Tags = append(best.mount.group.Tags(), match.Tags...)
+	match.Middleware = append(best.mount.group.Middleware(), match.Middleware...)
+	return match, true
+}
+
+// routeParamsKey 保存请求变量的context键
+type routeParamsKey struct{}
+
+// ParamsFromContext 返回RouteGroup处理请求时写入context的变量
+func ParamsFromContext(ctx context.Context) Params {
+	params, _ := ctx.Value(routeParamsKey{}).(Params)
+	return params
+}
+
+// ServeHTTP 查找路由并依次经过中间件调用处理器，没有匹配的路由时返回404
+/**
+ *挂载的分组树中的路由收到的请求中URL.Path为去除前缀后的路径。
+ */
+func (group *RouteGroup) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	match, ok := group.Lookup(request.URL.Path)
+	if !ok {
+		http.NotFound(writer, request)
+		return
+	}
+	if match.Path != request.URL.Path {
+		request = request.Clone(request.Context())
+		request.URL.Path = match.Path
+		request.URL.RawPath = ""
+	}
+	request = request.WithContext(context.WithValue(request.Context(), routeParamsKey{}, match.Params))
+	handler := match.Route.Handler
+	for i := len(match.Middleware) - 1; i >= 0; i-- {
+		handler = match.Middleware[i](handler)
+	}
+	handler.ServeHTTP(writer, request)
+}
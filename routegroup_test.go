@@ -0,0 +1,124 @@
+package antstyle
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// namedHandler 把名称与收到的路径写回响应的处理器
+func namedHandler(name string) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		io.WriteString(writer, name+" "+request.URL.Path)
+	})
+}
+
+// tagMiddleware 在响应中记录经过的中间件
+func tagMiddleware(name string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			io.WriteString(writer, name+">")
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+func TestRouteGroupCombinesPrefixes(t *testing.T) {
+	root := NewRouteGroup("/api")
+	users := root.Group("/users")
+	users.Handle("/{id}", namedHandler("user"))
+	root.Group("/hotels/*").Handle("booking", namedHandler("booking"))
+	want := []string{"/api/users/{id}", "/api/hotels/booking"}
+	if got := root.Patterns(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Patterns() = %v, want %v", got, want)
+	}
+	if got := users.Patterns(); !reflect.DeepEqual(got, want[:1]) {
+		t.Errorf("users.Patterns() = %v, want %v", got, want[:1])
+	}
+	match, ok := root.Lookup("/api/users/7")
+	if !ok || match.Pattern != "/api/users/{id}" || match.Params.ByName("id") != "7" {
+		t.Errorf("Lookup(/api/users/7) = %+v, %v", match, ok)
+	}
+}
+
+func TestRouteGroupMountRanksWithDirectRoutes(t *testing.T) {
+	root := NewRouteGroup("")
+	root.Handle("/**", namedHandler("fallback"))
+	admin := NewRouteGroup("")
+	admin.Handle("/users", namedHandler("users"))
+	root.Mount("/admin", admin)
+
+	match, ok := root.Lookup("/admin/users")
+	if !ok || match.Pattern != "/admin/users" || match.Path != "/users" {
+		t.Fatalf("Lookup(/admin/users) = %+v, %v", match, ok)
+	}
+	if match, ok := root.Lookup("/admin/other"); !ok || match.Pattern != "/**" {
+		t.Errorf("Lookup(/admin/other) = %+v, %v, want /**", match, ok)
+	}
+	want := []string{"/**", "/admin/users"}
+	if got := root.Patterns(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Patterns() = %v, want %v", got, want)
+	}
+}
+
+func TestRouteGroupMountUnderWildcardPrefix(t *testing.T) {
+	root := NewRouteGroup("")
+	sub := NewRouteGroup("")
+	sub.Handle("/users", namedHandler("users"))
+	root.Group("/api/*").Mount("", sub)
+
+	want := []string{"/api/*/users"}
+	if got := root.Patterns(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Patterns() = %v, want %v", got, want)
+	}
+	match, ok := root.Lookup("/api/v1/users")
+	if !ok || match.Pattern != "/api/*/users" || match.Path != "/users" {
+		t.Errorf("Lookup(/api/v1/users) = %+v, %v", match, ok)
+	}
+	if _, ok := root.Lookup("/api/v1/x/users"); ok {
+		t.Error("Lookup(/api/v1/x/users) matched")
+	}
+}
+
+func TestRouteGroupMountInheritsTagsAndMiddleware(t *testing.T) {
+	root := NewRouteGroup("").Tag("public").Use(tagMiddleware("root"))
+	tenants := root.Group("/tenants/{tenant}").Tag("tenant").Use(tagMiddleware("tenant"))
+	sub := NewRouteGroup("").Tag("admin").Use(tagMiddleware("sub"))
+	sub.Handle("/users/{id}", namedHandler("user"))
+	tenants.Mount("/admin", sub)
+
+	match, ok := root.Lookup("/tenants/acme/admin/users/7")
+	if !ok {
+		t.Fatal("Lookup did not match")
+	}
+	if want := []string{"public", "tenant", "admin"}; !reflect.DeepEqual(match.Tags, want) {
+		t.Errorf("Tags = %v, want %v", match.Tags, want)
+	}
+	if want := (Params{{"tenant", "acme"}, {"id", "7"}}); !reflect.DeepEqual(match.Params, want) {
+		t.Errorf("Params = %v, want %v", match.Params, want)
+	}
+
+	recorder := httptest.NewRecorder()
+	root.ServeHTTP(recorder, httptest.NewRequest("GET", "/tenants/acme/admin/users/7", nil))
+	if got, want := recorder.Body.String(), "root>tenant>sub>user /users/7"; got != want {
+		t.Errorf("ServeHTTP body = %q, want %q", got, want)
+	}
+	recorder = httptest.NewRecorder()
+	root.ServeHTTP(recorder, httptest.NewRequest("GET", "/missing", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("ServeHTTP(/missing) = %d, want 404", recorder.Code)
+	}
+}
+
+func TestRouteGroupDirectRouteTags(t *testing.T) {
+	root := NewRouteGroup("/api").Tag("api")
+	route := root.Group("/v1").Tag("v1").Handle("/ping", namedHandler("ping"))
+	if want := []string{"api", "v1"}; !reflect.DeepEqual(route.Tags(), want) {
+		t.Errorf("Route.Tags() = %v, want %v", route.Tags(), want)
+	}
+	if match, ok := root.Lookup("/api/v1/ping"); !ok || !reflect.DeepEqual(match.Tags, route.Tags()) {
+		t.Errorf("Lookup(/api/v1/ping) = %+v, %v", match, ok)
+	}
+}
@@ -0,0 +1,114 @@
+package antstyle
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SegmentKind 模式段的种类
+type SegmentKind int
+
+const (
+	LiteralSegment        SegmentKind = iota // 不含通配符的字面量段，例如"hotels"
+	GlobSegment                              // 含有"*"或"?"的段，例如"*.html"
+	VariableSegment                          // 含有"{name}"或"{name:regex}"的段
+	DoubleWildcardSegment                    // "**"，匹配0或者更多的目录
+)
+
+// String 返回段种类的名称
+func (kind SegmentKind) String() string {
+	switch kind {
+	case LiteralSegment:
+		return "literal"
+	case GlobSegment:
+		return "glob"
+	case VariableSegment:
+		return "variable"
+	case DoubleWildcardSegment:
+		return "doubleWildcard"
+	}
+	return "unknown"
+}
+
+// tokenKind 段内元素的种类
+type tokenKind int
+
+const (
+	literalToken tokenKind = iota // 单个字面量字符
+	anyToken                      // "?"，匹配任意单个字符
+	starToken                     // "*"或不带约束的"{name}"，匹配0或任意数量的字符
+	regexToken                    // "{name:regex}"，由正则表达式约束的变量
+)
+
+// segmentToken 段内的一个元素
+type segmentToken struct {
+	kind  tokenKind
+	char  rune   // literalToken的字符
+	name  string // 变量名
+	regex string // regexToken的约束表达式
+}
+
+// patternSegment 模式按路径分隔符切分后的一段
+type patternSegment struct {
+	text   string
+	kind   SegmentKind
+	tokens []segmentToken
+}
+
+// parseSegment 解析单个模式段，使用与patternBuilder相同的GlobPattern识别通配符与变量
+func parseSegment(text string) *patternSegment {
+	segment := &patternSegment{text: text, kind: LiteralSegment}
+	if text == "**" {
+		segment.kind = DoubleWildcardSegment
+		return segment
+	}
+	end := 0
+	for _, matched := range GlobPattern.FindAllStringIndex(text, MaxFindCount) {
+		segment.tokens = appendLiteralTokens(segment.tokens, text[end:matched[0]])
+		matchstr := text[matched[0]:matched[1]]
+		switch {
+		case matchstr == "?":
+			segment.tokens = append(segment.tokens, segmentToken{kind: anyToken})
+			if segment.kind == LiteralSegment {
+				segment.kind = GlobSegment
+			}
+		case matchstr == "*":
+			segment.tokens = append(segment.tokens, segmentToken{kind: starToken})
+			if segment.kind == LiteralSegment {
+				segment.kind = GlobSegment
+			}
+		default:
+			body := matchstr[1 : len(matchstr)-1]
+			colonIdx := strings.Index(body, ":")
+			if colonIdx == -1 {
+				segment.tokens = append(segment.tokens, segmentToken{kind: starToken, name: body})
+			} else {
+				segment.tokens = append(segment.tokens, segmentToken{kind: regexToken, name: body[:colonIdx], regex: body[colonIdx+1:]})
+			}
+			segment.kind = VariableSegment
+		}
+		end = matched[1]
+	}
+	segment.tokens = appendLiteralTokens(segment.tokens, text[end:])
+	return segment
+}
+
+// appendLiteralTokens 将字面量文本逐字符加入元素列表
+func appendLiteralTokens(tokens []segmentToken, literal string) []segmentToken {
+	for len(literal) > 0 {
+		r, size := utf8.DecodeRuneInString(literal)
+		tokens = append(tokens, segmentToken{kind: literalToken, char: r})
+		literal = literal[size:]
+	}
+	return tokens
+}
+
+// hasRegex 段中是否含有正则约束的变量
+func (segment *patternSegment) hasRegex() bool {
+	for _, token := range segment.tokens {
+		if token.kind == regexToken {
+			return true
+		}
+	}
+	return false
+}
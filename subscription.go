@@ -0,0 +1,149 @@
+package antstyle
+
+import (
+	"sort"
+	"sync"
+)
+
+const (
+	DestinationSeparator          = "."  // 消息目的地的分隔符，例如"orders.eu.created"
+	DefaultSubscriptionCacheLimit = 1024 // 默认缓存的目的地个数
+)
+
+// Subscription 一个会话对目的地模式的订阅
+type Subscription struct {
+	SessionID      string
+	SubscriptionID string
+	Destination    string // 订阅的目的地模式，例如"orders.*.created"
+}
+
+// SubscriptionRegistry 按目的地查找订阅者的注册表（线程安全）
+/**
+ *类似于Spring STOMP的DefaultSubscriptionRegistry，订阅以会话ID与订阅ID为键保存，
+ *目的地模式默认使用以"."分隔的AntPathMatcher进行匹配。
+ *FindSubscribers的结果按目的地缓存，订阅变化时只清除受影响的目的地。
+ */
+type SubscriptionRegistry struct {
+	matcher    PathMatcher
+	mutex      sync.RWMutex
+	sessions   map[string]map[string]string // 会话ID -> 订阅ID -> 目的地模式
+	cache      map[string][]Subscription    // 目的地 -> 订阅者
+	cacheLimit int
+	generation uint64 // 订阅每变化一次加一，避免将过期的查找结果写入缓存
+}
+
+// NewSubscriptionRegistry 使用以"."分隔的AntPathMatcher创建注册表
+func NewSubscriptionRegistry() *SubscriptionRegistry {
+	return NewSubscriptionRegistryWithMatcher(NewS(DestinationSeparator))
+}
+
+// NewSubscriptionRegistryWithMatcher 使用给定的PathMatcher创建注册表
+func NewSubscriptionRegistryWithMatcher(matcher PathMatcher) *SubscriptionRegistry {
+	return &SubscriptionRegistry{
+		matcher:    matcher,
+		sessions:   make(map[string]map[string]string),
+		cache:      make(map[string][]Subscription),
+		cacheLimit: DefaultSubscriptionCacheLimit,
+	}
+}
+
+// SetCacheLimit 设置最多缓存的目的地个数，超出时清空缓存，小于或等于0时不缓存
+func (registry *SubscriptionRegistry) SetCacheLimit(cacheLimit int) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.cacheLimit = cacheLimit
+	registry.cache = make(map[string][]Subscription)
+}
+
+// Subscribe 添加订阅，同一会话中已存在的订阅ID会被替换
+func (registry *SubscriptionRegistry) Subscribe(sessionID, subscriptionID, destination string) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	subscriptions, ok := registry.sessions[sessionID]
+	if !ok {
+		subscriptions = make(map[string]string)
+		registry.sessions[sessionID] = subscriptions
+	}
+	if previous, ok := subscriptions[subscriptionID]; ok {
+		registry.invalidate(previous)
+	}
+	subscriptions[subscriptionID] = destination
+	registry.invalidate(destination)
+}
+
+// Unsubscribe 删除订阅，返回订阅是否存在
+func (registry *SubscriptionRegistry) Unsubscribe(sessionID, subscriptionID string) bool {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	subscriptions := registry.sessions[sessionID]
+	destination, ok := subscriptions[subscriptionID]
+	if !ok {
+		return false
+	}
+	delete(subscriptions, subscriptionID)
+	if len(subscriptions) == 0 {
+		delete(registry.sessions, sessionID)
+	}
+	registry.invalidate(destination)
+	return true
+}
+
+// UnregisterSession 删除会话的所有订阅，通常在连接断开时调用
+func (registry *SubscriptionRegistry) UnregisterSession(sessionID string) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	for _, destination := range registry.sessions[sessionID] {
+		registry.invalidate(destination)
+	}
+	delete(registry.sessions, sessionID)
+}
+
+// FindSubscribers 返回所有订阅模式与目的地匹配的订阅，按会话ID与订阅ID排序
+/**
+ *与只返回最佳匹配的Registry不同，这里返回每一个匹配的订阅。
+ *@param destination 消息的目的地，例如"orders.eu.created"
+ *@return []Subscription 匹配的订阅，调用方可以修改返回的切片
+ */
+func (registry *SubscriptionRegistry) FindSubscribers(destination string) []Subscription {
+	registry.mutex.RLock()
+	if cached, ok := registry.cache[destination]; ok {
+		registry.mutex.RUnlock()
+		return append([]Subscription(nil), cached...)
+	}
+	generation := registry.generation
+	subscribers := make([]Subscription, 0)
+	for sessionID, subscriptions := range registry.sessions {
+		for subscriptionID, pattern := range subscriptions {
+			if registry.matcher.Match(pattern, destination) {
+				subscribers = append(subscribers, Subscription{SessionID: sessionID, SubscriptionID: subscriptionID, Destination: pattern})
+			}
+		}
+	}
+	registry.mutex.RUnlock()
+	sort.Slice(subscribers, func(i, j int) bool {
+		if subscribers[i].SessionID != subscribers[j].SessionID {
+			return subscribers[i].SessionID < subscribers[j].SessionID
+		}
+		return subscribers[i].SubscriptionID < subscribers[j].SubscriptionID
+	})
+
+	registry.mutex.Lock()
+	if registry.generation == generation && registry.cacheLimit > 0 {
+		if len(registry.cache) >= registry.cacheLimit {
+			registry.cache = make(map[string][]Subscription)
+		}
+		registry.cache[destination] = subscribers
+	}
+	registry.mutex.Unlock()
+	return append([]Subscription(nil), subscribers...)
+}
+
+// invalidate 清除与目的地模式匹配的缓存结果，调用方需持有写锁
+func (registry *SubscriptionRegistry) invalidate(pattern string) {
+	registry.generation++
+	for destination := range registry.cache {
+		if registry.matcher.Match(pattern, destination) {
+			delete(registry.cache, destination)
+		}
+	}
+}
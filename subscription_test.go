@@ -0,0 +1,123 @@
+package antstyle
+
+import (
+	"reflect"
+	"testing"
+)
+
+// subscriptionIDs 返回"会话ID/订阅ID"形式的标识，便于比较
+func subscriptionIDs(subscriptions []Subscription) []string {
+	ids := make([]string, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		ids = append(ids, subscription.SessionID+"/"+subscription.SubscriptionID)
+	}
+	return ids
+}
+
+func TestFindSubscribers(t *testing.T) {
+	registry := NewSubscriptionRegistry()
+	registry.Subscribe("s2", "b", "orders.**")
+	registry.Subscribe("s1", "b", "orders.*.created")
+	registry.Subscribe("s1", "a", "orders.eu.created")
+	registry.Subscribe("s3", "a", "orders.*.deleted")
+	registry.Subscribe("s3", "b", "payments.**")
+
+	got := registry.FindSubscribers("orders.eu.created")
+	want := []Subscription{
+		{SessionID: "s1", SubscriptionID: "a", Destination: "orders.eu.created"},
+		{SessionID: "s1", SubscriptionID: "b", Destination: "orders.*.created"},
+		{SessionID: "s2", SubscriptionID: "b", Destination: "orders.**"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindSubscribers = %v, want %v", got, want)
+	}
+	if got := subscriptionIDs(registry.FindSubscribers("orders.us.deleted")); !reflect.DeepEqual(got, []string{"s2/b", "s3/a"}) {
+		t.Errorf("FindSubscribers(orders.us.deleted) = %v", got)
+	}
+	if got := registry.FindSubscribers("users.created"); len(got) != 0 {
+		t.Errorf("FindSubscribers(users.created) = %v", got)
+	}
+}
+
+func TestSubscriptionChangesInvalidateCache(t *testing.T) {
+	registry := NewSubscriptionRegistry()
+	destination := "orders.eu.created"
+	registry.Subscribe("s1", "a", "orders.eu.*")
+	registry.FindSubscribers(destination)
+
+	steps := []struct {
+		name   string
+		change func()
+		want   []string
+	}{
+		{"Subscribe", func() { registry.Subscribe("s2", "a", "orders.**") }, []string{"s1/a", "s2/a"}},
+		{"SubscribeOtherSession", func() { registry.Subscribe("s2", "b", "orders.*.created") }, []string{"s1/a", "s2/a", "s2/b"}},
+		{"ReplaceSubscription", func() { registry.Subscribe("s1", "a", "payments.**") }, []string{"s2/a", "s2/b"}},
+		{"Unsubscribe", func() { registry.Unsubscribe("s2", "a") }, []string{"s2/b"}},
+		{"UnregisterSession", func() { registry.UnregisterSession("s2") }, []string{}},
+		{"SubscribeAgain", func() { registry.Subscribe("s1", "b", destination) }, []string{"s1/b"}},
+	}
+	for _, step := range steps {
+		step.change()
+		// 第二次查找来自缓存
+		for i := 0; i < 2; i++ {
+			if got := subscriptionIDs(registry.FindSubscribers(destination)); !reflect.DeepEqual(got, step.want) {
+				t.Errorf("after %s: FindSubscribers = %v, want %v", step.name, got, step.want)
+			}
+		}
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	registry := NewSubscriptionRegistry()
+	registry.Subscribe("s1", "a", "orders.**")
+	if registry.Unsubscribe("s1", "b") {
+		t.Errorf("Unsubscribe of unknown subscription returned true")
+	}
+	if registry.Unsubscribe("s2", "a") {
+		t.Errorf("Unsubscribe of unknown session returned true")
+	}
+	if !registry.Unsubscribe("s1", "a") {
+		t.Errorf("Unsubscribe of existing subscription returned false")
+	}
+	if registry.Unsubscribe("s1", "a") {
+		t.Errorf("second Unsubscribe returned true")
+	}
+	registry.UnregisterSession("s1")
+	if got := registry.FindSubscribers("orders.eu"); len(got) != 0 {
+		t.Errorf("FindSubscribers after Unsubscribe = %v", got)
+	}
+}
+
+func TestFindSubscribersReturnsCopy(t *testing.T) {
+	registry := NewSubscriptionRegistry()
+	registry.Subscribe("s1", "a", "orders.**")
+	registry.FindSubscribers("orders.eu")
+	registry.FindSubscribers("orders.eu")[0].SessionID = "changed"
+	if got := registry.FindSubscribers("orders.eu"); got[0].SessionID != "s1" {
+		t.Errorf("modifying the result changed the cached subscribers: %v", got)
+	}
+}
+
+func TestSubscriptionCacheLimit(t *testing.T) {
+	for _, limit := range []int{0, 1, 2} {
+		registry := NewSubscriptionRegistry()
+		registry.SetCacheLimit(limit)
+		registry.Subscribe("s1", "a", "orders.*")
+		for _, destination := range []string{"orders.eu", "orders.us", "orders.eu", "orders.ap", "payments.eu"} {
+			want := []string{"s1/a"}
+			if destination == "payments.eu" {
+				want = []string{}
+			}
+			if got := subscriptionIDs(registry.FindSubscribers(destination)); !reflect.DeepEqual(got, want) {
+				t.Errorf("limit %d: FindSubscribers(%q) = %v, want %v", limit, destination, got, want)
+			}
+		}
+		registry.mutex.RLock()
+		size := len(registry.cache)
+		registry.mutex.RUnlock()
+		if size > limit {
+			t.Errorf("limit %d: cache holds %d destinations", limit, size)
+		}
+	}
+}
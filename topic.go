@@ -0,0 +1,263 @@
+package antstyle
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	MQTTLevelSeparator   = "/" // MQTT主题层级的分隔符
+	MQTTSingleLevel      = "+" // MQTT单层通配符
+	MQTTMultiLevel       = "#" // MQTT多层通配符，只能出现在最后一层
+	AMQPWordSeparator    = "." // AMQP路由键单词的分隔符
+	AMQPSingleWord       = "*" // AMQP匹配一个单词的通配符
+	AMQPZeroOrMoreWords  = "#" // AMQP匹配0或者更多单词的通配符
+	systemTopicPrefix    = "$" // 以"$"开头的MQTT主题（例如"$SYS"）不会被首层的通配符匹配
+	topicLevelBufferSize = 32  // 匹配时在栈上为主题层级预留的空间
+)
+
+// MQTTToAnt 将MQTT主题过滤器转换为以"/"分隔的Ant模式
+/**
+ *"+"转换为"*"，"#"转换为"**"。
+ *Ant模式会合并空的层级，因此"a//b"与"a/b"转换后等价；需要严格的MQTT语义时使用MQTTMatcher。
+ *含有Ant通配符（"*"、"?"、"{"）的层级无法表示为字面量，返回错误。
+ */
+func MQTTToAnt(filter string) (string, error) {
+	if err := validateMQTTFilter(filter); err != nil {
+		return "", err
+	}
+	levels := strings.Split(filter, MQTTLevelSeparator)
+	for i, level := range levels {
+		switch level {
+		case MQTTSingleLevel:
+			levels[i] = "*"
+		case MQTTMultiLevel:
+			levels[i] = "**"
+		default:
+			if !isLiteralSegment(level) {
+				return "", fmt.Errorf("antstyle: MQTT level %q cannot be expressed as an Ant literal in %q", level, filter)
+			}
+		}
+	}
+	return strings.Join(levels, DefaultPathSeparator), nil
+}
+
+// AntToMQTT 将以"/"分隔的Ant模式转换为MQTT主题过滤器
+/**
+ *"*"与不带约束的"{name}"转换为"+"，"**"只能出现在最后一段并转换为"#"。
+ *开头与结尾的分隔符保留为空的层级。其他通配段无法用MQTT表示，返回错误。
+ */
+func AntToMQTT(pattern string) (string, error) {
+	segments := strings.Split(pattern, DefaultPathSeparator)
+	levels := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		if segment == "" && i != 0 && i != len(segments)-1 {
+			continue
+		}
+		level, err := antSegmentToTopicLevel(pattern, segment, MQTTSingleLevel, MQTTMultiLevel)
+		if err != nil {
+			return "", err
+		}
+		if level == MQTTMultiLevel && i != len(segments)-1 {
+			return "", fmt.Errorf("antstyle: \"**\" must be the last segment to convert %q to MQTT", pattern)
+		}
+		if level == segment && strings.ContainsAny(segment, MQTTSingleLevel+MQTTMultiLevel) {
+			return "", fmt.Errorf("antstyle: Ant segment %q cannot be expressed as an MQTT level in %q", segment, pattern)
+		}
+		levels = append(levels, level)
+	}
+	return strings.Join(levels, MQTTLevelSeparator), nil
+}
+
+// AMQPToAnt 将AMQP主题交换机的绑定键转换为以"."分隔的Ant模式，用于NewS(".")创建的AntPathMatcher
+/**
+ *"*"保持不变，"#"转换为"**"。与MQTTToAnt一样，空的单词在Ant模式中会被合并。
+ */
+func AMQPToAnt(bindingKey string) (string, error) {
+	words := strings.Split(bindingKey, AMQPWordSeparator)
+	for i, word := range words {
+		switch word {
+		case AMQPSingleWord:
+		case AMQPZeroOrMoreWords:
+			words[i] = "**"
+		default:
+			if !isLiteralSegment(word) {
+				return "", fmt.Errorf("antstyle: AMQP word %q cannot be expressed as an Ant literal in %q", word, bindingKey)
+			}
+		}
+	}
+	return strings.Join(words, AMQPWordSeparator), nil
+}
+
+// AntToAMQP 将以"."分隔的Ant模式转换为AMQP绑定键
+/**
+ *"*"与不带约束的"{name}"转换为"*"，"**"转换为"#"，其他通配段返回错误。
+ */
+func AntToAMQP(pattern string) (string, error) {
+	segments := strings.Split(pattern, AMQPWordSeparator)
+	words := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		word, err := antSegmentToTopicLevel(pattern, segment, AMQPSingleWord, AMQPZeroOrMoreWords)
+		if err != nil {
+			return "", err
+		}
+		if word == segment && word == AMQPZeroOrMoreWords {
+			return "", fmt.Errorf("antstyle: Ant segment %q cannot be expressed as an AMQP word in %q", segment, pattern)
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, AMQPWordSeparator), nil
+}
+
+// antSegmentToTopicLevel 将单个Ant模式段转换为主题层级
+func antSegmentToTopicLevel(pattern, segment, single, multi string) (string, error) {
+	parsed := parseSegment(segment)
+	switch parsed.kind {
+	case LiteralSegment:
+		return segment, nil
+	case DoubleWildcardSegment:
+		return multi, nil
+	}
+	if len(parsed.tokens) == 1 && parsed.tokens[0].kind == starToken {
+		return single, nil
+	}
+	return "", fmt.Errorf("antstyle: Ant segment %q cannot be expressed as a topic level in %q", segment, pattern)
+}
+
+// validateMQTTFilter 检查MQTT主题过滤器：不能为空，"+"与"#"必须占据整个层级，"#"只能出现在最后一层
+func validateMQTTFilter(filter string) error {
+	if filter == "" {
+		return fmt.Errorf("antstyle: MQTT topic filter must not be empty")
+	}
+	levels := strings.Split(filter, MQTTLevelSeparator)
+	for i, level := range levels {
+		if level == MQTTMultiLevel && i != len(levels)-1 {
+			return fmt.Errorf("antstyle: MQTT multi-level wildcard must be the last level in %q", filter)
+		}
+		if level != MQTTSingleLevel && level != MQTTMultiLevel && strings.ContainsAny(level, MQTTSingleLevel+MQTTMultiLevel) {
+			return fmt.Errorf("antstyle: MQTT wildcard must occupy an entire level in %q", filter)
+		}
+	}
+	return nil
+}
+
+// compileTopic 将按层级切分的主题过滤器转换为compiledPattern
+/**
+ *字面量层级即使含有Ant通配符也按字面量比较，单层通配符转换为匹配整段的"*"，多层通配符转换为"**"，
+ *之后由doMatch使用的matchSegments完成匹配。空的层级被保留为空的字面量段。
+ */
+func (ant *AntPathMatcher) compileTopic(filter string, levels []string, single, multi string) *compiledPattern {
+	compiled := &compiledPattern{pattern: filter, segments: make([]compiledSegment, 0, len(levels))}
+	for _, level := range levels {
+		segment := compiledSegment{text: level, lower: strings.ToLower(level), kind: LiteralSegment}
+		switch level {
+		case single:
+			segment = compiledSegment{text: "*", lower: "*", kind: GlobSegment, matcher: ant.getStringMatcher("*")}
+		case multi:
+			segment = compiledSegment{text: "**", lower: "**", kind: DoubleWildcardSegment}
+		}
+		compiled.segments = append(compiled.segments, segment)
+	}
+	return compiled
+}
+
+// splitTopic 按分隔符切分主题，保留空的层级，空字符串没有任何层级
+func splitTopic(topic, separator string, buf []string) []string {
+	if topic == "" {
+		return buf
+	}
+	for {
+		idx := strings.Index(topic, separator)
+		if idx == -1 {
+			return append(buf, topic)
+		}
+		buf = append(buf, topic[:idx])
+		topic = topic[idx+len(separator):]
+	}
+}
+
+// MQTTMatcher 按MQTT规范匹配主题过滤器与主题名（线程安全）
+/**
+ *"+"匹配恰好一个层级（可以为空），"#"匹配父层级及其下任意数量的层级，
+ *以"$"开头的主题不会被首层为"+"或"#"的过滤器匹配。匹配区分大小写，空的层级有意义。
+ */
+type MQTTMatcher struct {
+	ant   *AntPathMatcher
+	cache PatternCache
+}
+
+// NewMQTTMatcher 构造函数
+func NewMQTTMatcher() *MQTTMatcher {
+	return &MQTTMatcher{ant: New(), cache: NewMapPatternCache()}
+}
+
+// ValidateFilter 检查主题过滤器是否符合MQTT规范
+func (matcher *MQTTMatcher) ValidateFilter(filter string) error {
+	return validateMQTTFilter(filter)
+}
+
+// Match 判断主题名是否与主题过滤器匹配，过滤器无效或主题名含有通配符时返回false
+func (matcher *MQTTMatcher) Match(filter, topic string) bool {
+	if topic == "" || strings.ContainsAny(topic, MQTTSingleLevel+MQTTMultiLevel) {
+		return false
+	}
+	compiled := matcher.compile(filter)
+	if compiled == nil {
+		return false
+	}
+	if strings.HasPrefix(topic, systemTopicPrefix) {
+		first := compiled.segments[0]
+		if first.kind != LiteralSegment {
+			return false
+		}
+	}
+	var buf [topicLevelBufferSize]string
+	return matcher.ant.matchSegments(compiled, splitTopic(topic, MQTTLevelSeparator, buf[:0]), false, true, nil)
+}
+
+// compile 返回缓存的过滤器，过滤器无效时返回nil
+func (matcher *MQTTMatcher) compile(filter string) *compiledPattern {
+	key := PatternKey{Pattern: filter}
+	if value, ok := matcher.cache.Get(key); ok {
+		return value.(*compiledPattern)
+	}
+	if validateMQTTFilter(filter) != nil {
+		return nil
+	}
+	compiled := matcher.ant.compileTopic(filter, strings.Split(filter, MQTTLevelSeparator), MQTTSingleLevel, MQTTMultiLevel)
+	matcher.cache.Put(key, compiled)
+	return compiled
+}
+
+// AMQPMatcher 按AMQP主题交换机的规则匹配绑定键与路由键（线程安全）
+/**
+ *单词以"."分隔，"*"匹配恰好一个单词，"#"可以出现在任意位置并匹配0或者更多单词。
+ *"*"与"#"只有占据整个单词时才是通配符。匹配区分大小写，空的单词有意义，空字符串表示没有单词。
+ */
+type AMQPMatcher struct {
+	ant   *AntPathMatcher
+	cache PatternCache
+}
+
+// NewAMQPMatcher 构造函数
+func NewAMQPMatcher() *AMQPMatcher {
+	return &AMQPMatcher{ant: NewS(AMQPWordSeparator), cache: NewMapPatternCache()}
+}
+
+// Match 判断路由键是否与绑定键匹配
+func (matcher *AMQPMatcher) Match(bindingKey, routingKey string) bool {
+	key := PatternKey{Pattern: bindingKey}
+	var compiled *compiledPattern
+	if value, ok := matcher.cache.Get(key); ok {
+		compiled = value.(*compiledPattern)
+	} else {
+		var levels [topicLevelBufferSize]string
+		compiled = matcher.ant.compileTopic(bindingKey, splitTopic(bindingKey, AMQPWordSeparator, levels[:0]), AMQPSingleWord, AMQPZeroOrMoreWords)
+		matcher.cache.Put(key, compiled)
+	}
+	var buf [topicLevelBufferSize]string
+	return matcher.ant.matchSegments(compiled, splitTopic(routingKey, AMQPWordSeparator, buf[:0]), false, true, nil)
+}
@@ -0,0 +1,147 @@
+package antstyle
+
+import "testing"
+
+func TestMQTTMatcher(t *testing.T) {
+	cases := []struct {
+		filter string
+		topic  string
+		want   bool
+	}{
+		{"sport/tennis/player1/#", "sport/tennis/player1", true},
+		{"sport/tennis/player1/#", "sport/tennis/player1/ranking", true},
+		{"sport/tennis/player1/#", "sport/tennis/player1/score/wimbledon", true},
+		{"sport/#", "sport", true},
+		{"#", "sport/tennis", true},
+		{"sport/tennis/+", "sport/tennis/player1", true},
+		{"sport/tennis/+", "sport/tennis/player1/ranking", false},
+		{"sport/tennis/+", "sport/tennis", false},
+		{"sport/+", "sport", false},
+		{"sport/+", "sport/", true},
+		{"+/+", "/finance", true},
+		{"/+", "/finance", true},
+		{"+", "/finance", false},
+		{"a//b", "a//b", true},
+		{"a//b", "a/b", false},
+		{"a/+/b", "a//b", true},
+		{"Sport", "sport", false},
+		{"#", "$SYS/monitor", false},
+		{"+/monitor", "$SYS/monitor", false},
+		{"$SYS/#", "$SYS/monitor", true},
+		{"$SYS/+", "$SYS/monitor", true},
+		{"sport/tennis#", "sport/tennis", false},
+		{"sport/#/ranking", "sport/x/ranking", false},
+		{"sport+", "sport+", false},
+		{"sport/+", "sport/+", false},
+		{"#", "", false},
+		{"a/*", "a/b", false},
+		{"a/*", "a/*", true},
+	}
+	matcher := NewMQTTMatcher()
+	for _, c := range cases {
+		if got := matcher.Match(c.filter, c.topic); got != c.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", c.filter, c.topic, got, c.want)
+		}
+	}
+}
+
+func TestMQTTValidateFilter(t *testing.T) {
+	matcher := NewMQTTMatcher()
+	for _, filter := range []string{"#", "+", "a/+/b/#", "/", "a//b", "$SYS/#"} {
+		if err := matcher.ValidateFilter(filter); err != nil {
+			t.Errorf("ValidateFilter(%q) = %v", filter, err)
+		}
+	}
+	for _, filter := range []string{"", "a/#/b", "a#", "a/b+", "+a"} {
+		if err := matcher.ValidateFilter(filter); err == nil {
+			t.Errorf("ValidateFilter(%q) returned no error", filter)
+		}
+	}
+}
+
+func TestAMQPMatcher(t *testing.T) {
+	cases := []struct {
+		bindingKey string
+		routingKey string
+		want       bool
+	}{
+		{"*.orange.*", "quick.orange.rabbit", true},
+		{"*.orange.*", "quick.orange.male.rabbit", false},
+		{"*.orange.*", "orange", false},
+		{"*.*.rabbit", "lazy.orange.rabbit", true},
+		{"lazy.#", "lazy", true},
+		{"lazy.#", "lazy.orange.male.rabbit", true},
+		{"#", "", true},
+		{"#", "a.b.c", true},
+		{"*", "", false},
+		{"*", "a", true},
+		{"a.*", "a.", true},
+		{"a.#.z", "a.z", true},
+		{"a.#.z", "a.b.c.z", true},
+		{"a.#.z", "a.b.c", false},
+		{"#.middle.#", "middle", true},
+		{"#.middle.#", "x.middle.y.z", true},
+		{"#.middle.#", "x.muddle.y", false},
+		{"a*", "a*", true},
+		{"a*", "ab", false},
+		{"Lazy", "lazy", false},
+		{"", "", true},
+		{"", "a", false},
+	}
+	matcher := NewAMQPMatcher()
+	for _, c := range cases {
+		if got := matcher.Match(c.bindingKey, c.routingKey); got != c.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", c.bindingKey, c.routingKey, got, c.want)
+		}
+	}
+}
+
+func TestTopicConverters(t *testing.T) {
+	cases := []struct {
+		name    string
+		convert func(string) (string, error)
+		input   string
+		want    string
+	}{
+		{"MQTTToAnt", MQTTToAnt, "sport/+/#", "sport/*/**"},
+		{"MQTTToAnt", MQTTToAnt, "/a/b", "/a/b"},
+		{"AntToMQTT", AntToMQTT, "/sport/*/{id}/**", "/sport/+/+/#"},
+		{"AntToMQTT", AntToMQTT, "a//b/", "a/b/"},
+		{"AMQPToAnt", AMQPToAnt, "a.*.#", "a.*.**"},
+		{"AMQPToAnt", AMQPToAnt, "#.x.#", "**.x.**"},
+		{"AntToAMQP", AntToAMQP, "a.*.{x}.**", "a.*.*.#"},
+		{"AntToAMQP", AntToAMQP, "**.x", "#.x"},
+	}
+	for _, c := range cases {
+		if got, err := c.convert(c.input); err != nil || got != c.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", c.name, c.input, got, err, c.want)
+		}
+	}
+}
+
+func TestTopicConverterErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		convert func(string) (string, error)
+		input   string
+	}{
+		{"MQTTToAnt", MQTTToAnt, ""},
+		{"MQTTToAnt", MQTTToAnt, "sport/#/x"},
+		{"MQTTToAnt", MQTTToAnt, "sport/tennis#"},
+		{"MQTTToAnt", MQTTToAnt, "a/b*"},
+		{"MQTTToAnt", MQTTToAnt, "a/{b}"},
+		{"AntToMQTT", AntToMQTT, "/a/**/b"},
+		{"AntToMQTT", AntToMQTT, "/a/*.json"},
+		{"AntToMQTT", AntToMQTT, "/a/b+c"},
+		{"AntToMQTT", AntToMQTT, "/a/{id:\\d+}"},
+		{"AMQPToAnt", AMQPToAnt, "a.b?"},
+		{"AMQPToAnt", AMQPToAnt, "a.{b}"},
+		{"AntToAMQP", AntToAMQP, "a.x*"},
+		{"AntToAMQP", AntToAMQP, "a.#"},
+	}
+	for _, c := range cases {
+		if got, err := c.convert(c.input); err == nil {
+			t.Errorf("%s(%q) = %q, want error", c.name, c.input, got)
+		}
+	}
+}
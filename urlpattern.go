@@ -0,0 +1,132 @@
+package antstyle
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+var (
+	urlPathMatcher   *AntPathMatcher // 匹配路径，以"/"分隔
+	urlHostMatcher   *HostMatcher    // 匹配主机名，以"."分隔
+	urlSchemeMatcher *AntPathMatcher // 将协议作为整体匹配，不区分大小写
+	urlValueMatcher  *AntPathMatcher // 将端口与查询参数的值作为整体匹配
+)
+
+func init() {
+	urlPathMatcher = New()
+	urlHostMatcher = NewHostMatcher()
+	urlSchemeMatcher = New()
+	urlSchemeMatcher.SetCaseSensitive(false)
+	urlValueMatcher = New()
+}
+
+// URLPattern 按组成部分匹配完整URL的模式，类似于WHATWG的URLPattern
+/**
+ *每个组成部分各自使用Ant模式，为空字符串时匹配任意值：
+ *Path以"/"分隔；Host以"."分隔并按HostMatcher的规则匹配（不含端口）；
+ *Scheme、Port与Query中的值作为一个整体匹配，其中的"*"也匹配"/"。
+ *Query中的每个参数都必须出现在URL中，且至少有一个值与对应的模式匹配，URL中多余的参数不影响匹配。
+ *例如{Scheme: "https", Host: "{tenant}.example.com", Path: "/api/**", Query: {"v": "{version}"}}。
+ */
+type URLPattern struct {
+	Scheme string
+	Host   string
+	Port   string
+	Path   string
+	Query  map[string]string // 参数名 -> 值的模式
+}
+
+// Test 判断URL是否与模式匹配
+func (pattern *URLPattern) Test(u *url.URL) bool {
+	_, ok := pattern.Exec(u)
+	return ok
+}
+
+// Exec 匹配URL并返回所有组成部分中的URI模板变量
+/**
+ *变量依次来自Scheme、Host、Port、Path以及按参数名排序的Query。
+ *解码后的路径中含有"."或".."段时（例如"/public/../admin"或"/public/..%2Fadmin"）一律不匹配，
+ *避免按模式放行的URL在上游解析后指向模式之外的路径。
+ *@param u 要匹配的URL
+ *@return Params 所有组成部分中的变量
+ *@return bool 是否匹配
+ */
+func (pattern *URLPattern) Exec(u *url.URL) (Params, bool) {
+	params := make(Params, 0)
+	if !matchWholeValue(urlSchemeMatcher, pattern.Scheme, u.Scheme, &params) {
+		return nil, false
+	}
+	if pattern.Host != "" {
+		hostParams := make(Params, 0)
+		if !urlHostMatcher.ExtractParams(pattern.Host, u.Hostname(), &hostParams) {
+			return nil, false
+		}
+		params = append(params, hostParams...)
+	}
+	if !matchWholeValue(urlValueMatcher, pattern.Port, u.Port(), &params) {
+		return nil, false
+	}
+	if pattern.Path != "" {
+		path := u.Path
+		if hasDotSegment(path) {
+			return nil, false
+		}
+		if path == "" {
+			path = DefaultPathSeparator
+		}
+		pathParams := make(Params, 0)
+		if !urlPathMatcher.ExtractParams(pattern.Path, path, &pathParams) {
+			return nil, false
+		}
+		params = append(params, pathParams...)
+	}
+	if len(pattern.Query) > 0 {
+		names := make([]string, 0, len(pattern.Query))
+		for name := range pattern.Query {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		query := u.Query()
+		for _, name := range names {
+			values, ok := query[name]
+			if !ok {
+				return nil, false
+			}
+			matched := false
+			for _, value := range values {
+				if matchWholeValue(urlValueMatcher, pattern.Query[name], value, &params) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, false
+			}
+		}
+	}
+	return params, true
+}
+
+// hasDotSegment 路径中是否含有"."或".."段
+func hasDotSegment(path string) bool {
+	for _, segment := range strings.Split(path, DefaultPathSeparator) {
+		if segment == "." || segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWholeValue 将值作为一个整体与模式匹配，匹配时将变量追加到params，模式为空时匹配任意值
+func matchWholeValue(ant *AntPathMatcher, pattern, value string, params *Params) bool {
+	if pattern == "" {
+		return true
+	}
+	mark := len(*params)
+	if !ant.getStringMatcher(pattern).matchParams(value, params) {
+		*params = (*params)[:mark]
+		return false
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package antstyle
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestURLPatternTest(t *testing.T) {
+	cases := []struct {
+		name    string
+		pattern URLPattern
+		url     string
+		want    bool
+	}{
+		{"AnyURL", URLPattern{}, "ftp://x.example.com:21/a?b=c", true},
+		{"Scheme", URLPattern{Scheme: "https"}, "https://example.com/", true},
+		{"SchemeIgnoresCase", URLPattern{Scheme: "https"}, "HTTPS://example.com/", true},
+		{"SchemeMismatch", URLPattern{Scheme: "https"}, "http://example.com/", false},
+		{"SchemeWildcard", URLPattern{Scheme: "http*"}, "https://example.com/", true},
+		{"Host", URLPattern{Host: "*.example.com"}, "https://api.example.com/", true},
+		{"HostIgnoresPort", URLPattern{Host: "api.example.com"}, "https://api.example.com:8443/", true},
+		{"HostMismatch", URLPattern{Host: "*.example.com"}, "https://example.org/", false},
+		{"Port", URLPattern{Port: "8443"}, "https://example.com:8443/", true},
+		{"PortMismatch", URLPattern{Port: "8443"}, "https://example.com:9443/", false},
+		{"PortMissing", URLPattern{Port: "8443"}, "https://example.com/", false},
+		{"Path", URLPattern{Path: "/public/**"}, "https://example.com/public/a/b", true},
+		{"PathEmptyIsRoot", URLPattern{Path: "/"}, "https://example.com", true},
+		{"PathMismatch", URLPattern{Path: "/public/**"}, "https://example.com/admin", false},
+		{"Query", URLPattern{Query: map[string]string{"v": "2*"}}, "https://example.com/?v=20", true},
+		{"QueryAnyValue", URLPattern{Query: map[string]string{"v": "2*"}}, "https://example.com/?v=1&v=21", true},
+		{"QueryExtraParams", URLPattern{Query: map[string]string{"v": "*"}}, "https://example.com/?v=1&w=2", true},
+		{"QueryMissing", URLPattern{Query: map[string]string{"v": "*"}}, "https://example.com/?w=2", false},
+		{"QueryValueWithSlash", URLPattern{Query: map[string]string{"next": "/home*"}}, "https://example.com/?next=/home/a", true},
+	}
+	for _, c := range cases {
+		u, err := url.Parse(c.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.pattern.Test(u); got != c.want {
+			t.Errorf("%s: Test(%q) = %v, want %v", c.name, c.url, got, c.want)
+		}
+	}
+}
+
+func TestURLPatternRejectsDotSegments(t *testing.T) {
+	pattern := URLPattern{Host: "api.example.com", Path: "/public/**"}
+	cases := []string{
+		"https://api.example.com/public/../admin",
+		"https://api.example.com/public/..%2Fadmin",
+		"https://api.example.com/public/%2e%2e/admin",
+		"https://api.example.com/public/a/../../admin",
+		"https://api.example.com/public/./a",
+	}
+	for _, raw := range cases {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pattern.Test(u) {
+			t.Errorf("Test(%q) = true, want false", raw)
+		}
+	}
+}
+
+func TestURLPatternExecVariableOrder(t *testing.T) {
+	pattern := URLPattern{
+		Scheme: "{scheme}",
+		Host:   "{tenant}.example.com",
+		Port:   "{port}",
+		Path:   "/api/{version}/**",
+		Query:  map[string]string{"b": "{second}", "a": "{first}"},
+	}
+	u, err := url.Parse("https://acme.example.com:8443/api/v2/users?b=2&a=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, ok := pattern.Exec(u)
+	if !ok {
+		t.Fatal("Exec did not match")
+	}
+	want := Params{
+		{"scheme", "https"},
+		{"tenant", "acme"},
+		{"port", "8443"},
+		{"version", "v2"},
+		{"first", "1"},
+		{"second", "2"},
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("Exec = %v, want %v", params, want)
+	}
+}